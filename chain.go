@@ -0,0 +1,255 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ChainedConf is a Conf compatible object that layers several Conf objects over each other.
+//
+// Reads are resolved top-down, the first layer with an explicit value for the key provides it, and sections
+// are merged from every layer. Defaults (eg. from SetDefault or a Registry) only apply when no layer has an
+// explicit value, so a default in the top layer does not hide a value set in a lower layer. Layers are read on
+// every call, so later changes to any layer are reflected without copying. Writes and Save go to the writable
+// layer, which is the top layer unless SetWritable is used.
+type ChainedConf struct {
+	lock     sync.Mutex
+	layers   []Conf
+	writable int
+}
+
+// ChainConf returns a ChainedConf that reads from the layers in order, the first layer takes precedence.
+// ChainConf panics if no layers are supplied.
+func ChainConf(layers ...Conf) *ChainedConf {
+	if len(layers) == 0 {
+		panic("config: ChainConf requires at least one layer")
+	}
+
+	return &ChainedConf{layers: slices.Clone(layers)}
+}
+
+// SetWritable sets the layer, by its index in the chain, that receives writes and is saved by Save.
+// SetWritable panics if the index is out of range.
+func (c *ChainedConf) SetWritable(index int) {
+	_ = c.layers[index]
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writable = index
+}
+
+// Writable returns the layer that receives writes and is saved by Save.
+func (c *ChainedConf) Writable() Conf {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.layers[c.writable]
+}
+
+// explicitConf is implemented by the Conf objects that distinguish explicit values from defaults.
+type explicitConf interface {
+	explicitSettings() map[string]interface{}
+}
+
+// explicitSettings returns the settings of the layers that are not defaults, the first layer takes precedence.
+func (c *ChainedConf) explicitSettings() map[string]interface{} {
+	out := map[string]interface{}{}
+
+	for _, l := range slices.Backward(c.layers) {
+		if e, ok := l.(explicitConf); ok {
+			mergeSettings(out, e.explicitSettings())
+		} else {
+			mergeSettings(out, l.AllSettings())
+		}
+	}
+
+	return out
+}
+
+// hasExplicit returns true if the layer has a value for the key that is not a default.
+func hasExplicit(l Conf, key string) bool {
+	if e, ok := l.(explicitConf); ok {
+		_, found := lookupKey(e.explicitSettings(), key)
+
+		return found
+	}
+
+	return l.IsSet(key)
+}
+
+// saveOverride returns a function that restores the key in the writable layer to its current state, or nil if
+// the writable layer can not restore it.
+func (c *ChainedConf) saveOverride(key string) func() {
+	if o, ok := c.Writable().(overrider); ok {
+		return o.saveOverride(key)
+	}
+
+	return nil
+}
+
+// layer returns the first layer that has an explicit value for the key, then the first layer with a default
+// for it, or the writable layer if none do.
+func (c *ChainedConf) layer(key string) Conf {
+	for _, l := range c.layers {
+		if hasExplicit(l, key) {
+			return l
+		}
+	}
+
+	for _, l := range c.layers {
+		if l.IsSet(key) {
+			return l
+		}
+	}
+
+	return c.Writable()
+}
+
+// Get can retrieve any value given the key to use.
+// Get is case-insensitive for a key.
+func (c *ChainedConf) Get(key string) interface{} {
+	val := c.layer(key).Get(key)
+
+	if _, ok := val.(map[string]interface{}); ok {
+		val, _ = lookupKey(c.AllSettings(), key)
+	}
+
+	return val
+}
+
+// GetBool returns the value associated with the key as a boolean.
+func (c *ChainedConf) GetBool(key string) bool {
+	return c.layer(key).GetBool(key)
+}
+
+// GetDuration returns the value associated with the key as a duration.
+func (c *ChainedConf) GetDuration(key string) time.Duration {
+	return c.layer(key).GetDuration(key)
+}
+
+// GetFloat64 returns the value associated with the key as a float64.
+func (c *ChainedConf) GetFloat64(key string) float64 {
+	return c.layer(key).GetFloat64(key)
+}
+
+// GetInt returns the value associated with the key as an int.
+func (c *ChainedConf) GetInt(key string) int {
+	return c.layer(key).GetInt(key)
+}
+
+// GetIntSlice returns the value associated with the key as a slice of ints.
+func (c *ChainedConf) GetIntSlice(key string) []int {
+	return c.layer(key).GetIntSlice(key)
+}
+
+// GetString returns the value associated with the key as a string.
+func (c *ChainedConf) GetString(key string) string {
+	return c.layer(key).GetString(key)
+}
+
+// GetStringSlice returns the value associated with the key as a slice of strings.
+func (c *ChainedConf) GetStringSlice(key string) []string {
+	return c.layer(key).GetStringSlice(key)
+}
+
+// Set sets the value for the key in the writable layer.
+func (c *ChainedConf) Set(key string, value interface{}) {
+	c.Writable().Set(key, value)
+}
+
+// SetBool sets the value for the key in the writable layer.
+func (c *ChainedConf) SetBool(key string, value bool) {
+	c.Writable().SetBool(key, value)
+}
+
+// SetDuration sets the value for the key in the writable layer.
+func (c *ChainedConf) SetDuration(key string, value time.Duration) {
+	c.Writable().SetDuration(key, value)
+}
+
+// SetFloat64 sets the value for the key in the writable layer.
+func (c *ChainedConf) SetFloat64(key string, value float64) {
+	c.Writable().SetFloat64(key, value)
+}
+
+// SetInt sets the value for the key in the writable layer.
+func (c *ChainedConf) SetInt(key string, value int) {
+	c.Writable().SetInt(key, value)
+}
+
+// SetIntSlice sets the value for the key in the writable layer.
+func (c *ChainedConf) SetIntSlice(key string, value []int) {
+	c.Writable().SetIntSlice(key, value)
+}
+
+// SetString sets the value for the key in the writable layer.
+func (c *ChainedConf) SetString(key string, value string) {
+	c.Writable().SetString(key, value)
+}
+
+// SetStringSlice sets the value for the key in the writable layer.
+func (c *ChainedConf) SetStringSlice(key string, value []string) {
+	c.Writable().SetStringSlice(key, value)
+}
+
+// IsSet returns true if the key has a value in any layer.
+func (c *ChainedConf) IsSet(key string) bool {
+	for _, l := range c.layers {
+		if l.IsSet(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unset removes the key (or section) from the writable layer, values in the other layers are still visible.
+func (c *ChainedConf) Unset(key string) {
+	c.Writable().Unset(key)
+}
+
+// AllKeys returns every key that has a value in any layer, sorted.
+func (c *ChainedConf) AllKeys() []string {
+	keys := flattenKeys(c.AllSettings(), "")
+	slices.Sort(keys)
+
+	return keys
+}
+
+// AllSettings returns the settings of every layer merged, the first layer takes precedence and explicit values
+// take precedence over defaults.
+func (c *ChainedConf) AllSettings() map[string]interface{} {
+	out := map[string]interface{}{}
+
+	for _, l := range slices.Backward(c.layers) {
+		mergeSettings(out, l.AllSettings())
+	}
+
+	mergeSettings(out, c.explicitSettings())
+
+	return out
+}
+
+// ZapConfig returns a zap logger configuration derived from the merged settings.
+func (c *ChainedConf) ZapConfig() zap.Config {
+	return newSnapshot(c.AllSettings()).ZapConfig()
+}
+
+// SlogOptions returns slog handler options derived from the merged settings.
+func (c *ChainedConf) SlogOptions() *slog.HandlerOptions {
+	return newSnapshot(c.AllSettings()).SlogOptions()
+}
+
+// SlogHandler returns a slog handler derived from the merged settings.
+func (c *ChainedConf) SlogHandler() (slog.Handler, error) {
+	return newSnapshot(c.AllSettings()).SlogHandler()
+}
+
+// Save saves the writable layer.
+func (c *ChainedConf) Save() error {
+	return c.Writable().Save()
+}
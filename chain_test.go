@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestChainConf_Reads(t *testing.T) {
+	system, err := config.New("test-project",
+		config.WithConfigFiles("testdata/test-project.toml"),
+		config.WithConfD("testdata/conf.d"),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	tenant := config.NewMemoryConf(map[string]interface{}{
+		"server": map[string]interface{}{"address": "10.0.0.1:8080"},
+	})
+
+	vcfg := config.ChainConf(tenant, system)
+
+	expectGetString(t, vcfg, "server.address", "10.0.0.1:8080")
+	expectGetString(t, vcfg, "category3.second", "foobar")
+	expectGetInt(t, vcfg, "category1.int", 8008)
+
+	system.SetString("category3.second", "changed")
+	expectGetString(t, vcfg, "category3.second", "changed")
+
+	system.SetString("server.port", "9090")
+
+	if diff := cmp.Diff(vcfg.Get("server"), map[string]interface{}{
+		"address": "10.0.0.1:8080",
+		"port":    "9090",
+	}); diff != "" {
+		t.Errorf("config.Get(): -got +want:\n%s", diff)
+	}
+}
+
+func TestChainConf_DefaultsDoNotShadow(t *testing.T) {
+	dir := t.TempDir()
+	systemFile, tenantFile := filepath.Join(dir, "system.toml"), filepath.Join(dir, "tenant.toml")
+	writeTestFile(t, systemFile, "[server]\nport = 9999\n")
+	writeTestFile(t, tenantFile, "[server]\naddress = \"10.0.0.1\"\n")
+
+	system, err := config.New("system", config.WithConfigFiles(systemFile))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	tenant, err := config.New("tenant", config.WithConfigFiles(tenantFile))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	tenant.(*config.ViperConf).SetDefault("server.port", 80)
+	tenant.(*config.ViperConf).SetDefault("server.timeout", 30)
+
+	vcfg := config.ChainConf(tenant, system)
+
+	expectGetInt(t, vcfg, "server.port", 9999)
+	expectGetInt(t, vcfg, "server.timeout", 30)
+
+	if diff := cmp.Diff(vcfg.Get("server"), map[string]interface{}{
+		"address": "10.0.0.1",
+		"port":    int64(9999),
+		"timeout": 30,
+	}); diff != "" {
+		t.Errorf("config.Get(): -got +want:\n%s", diff)
+	}
+
+	tenant.SetInt("server.port", 8080)
+	expectGetInt(t, vcfg, "server.port", 8080)
+}
+
+func TestChainConf_Writes(t *testing.T) {
+	system := config.NewMemoryConf(map[string]interface{}{"server": map[string]interface{}{"port": 8080}})
+	tenant := config.NewMemoryConf(nil)
+
+	buf := bytes.NewBuffer(nil)
+	system.SetSaveWriter(buf)
+
+	vcfg := config.ChainConf(tenant, system)
+	vcfg.SetInt("server.port", 9090)
+
+	expectGetInt(t, tenant, "server.port", 9090)
+	expectGetInt(t, system, "server.port", 8080)
+	expectGetInt(t, vcfg, "server.port", 9090)
+
+	vcfg.Unset("server.port")
+	expectGetInt(t, vcfg, "server.port", 8080)
+
+	vcfg.SetWritable(1)
+	vcfg.SetInt("server.port", 7070)
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(buf.String(), "\n[server]\n  port = 7070\n"); diff != "" {
+		t.Errorf("config.Save(): -got +want:\n%s", diff)
+	}
+}
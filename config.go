@@ -1,8 +1,10 @@
 package config
 
 import (
+	"log/slog"
 	"time"
 
+	"github.com/na4ma4/config/internal/override"
 	"go.uber.org/zap"
 )
 
@@ -26,7 +28,35 @@ type Conf interface {
 	SetString(key string, value string)
 	SetStringSlice(key string, value []string)
 
+	IsSet(key string) bool
+	Unset(key string)
+	AllKeys() []string
+	AllSettings() map[string]interface{}
+
 	ZapConfig() zap.Config
+	SlogOptions() *slog.HandlerOptions
+	SlogHandler() (slog.Handler, error)
 	Save() error
 	// Write(out io.Writer) error
 }
+
+// overrider is implemented by the Conf objects that can restore the runtime value of a key, eg. for
+// conftest.Override, without leaving it pinned in (or unset from) the override layer.
+type overrider interface {
+	// saveOverride returns a function that restores the runtime value of the key to its current state, or nil
+	// if it can not be restored.
+	saveOverride(key string) func()
+}
+
+//nolint:gochecknoinits // hooks conftest into the override layer without exporting it.
+func init() {
+	override.Save = func(c interface{}, key string) (func(), bool) {
+		if o, ok := c.(overrider); ok {
+			restore := o.saveOverride(key)
+
+			return restore, restore != nil
+		}
+
+		return nil, false
+	}
+}
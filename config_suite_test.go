@@ -5,28 +5,23 @@ import (
 	"time"
 
 	"github.com/na4ma4/config"
+	"github.com/na4ma4/config/conftest"
 )
 
 func expectGetString(t *testing.T, vcfg config.Conf, key, expectValue string) {
 	t.Helper()
 
-	if v := vcfg.GetString(key); v != expectValue {
-		t.Errorf("GetString(): got '%s', want '%s'", v, expectValue)
-	}
+	conftest.ExpectString(t, vcfg, key, expectValue)
 }
 
 func expectGetDuration(t *testing.T, vcfg config.Conf, key string, expectValue time.Duration) {
 	t.Helper()
 
-	if v := vcfg.GetDuration(key); v.String() != expectValue.String() {
-		t.Errorf("GetDuration(): got '%s', want '%s'", v.String(), expectValue.String())
-	}
+	conftest.ExpectDuration(t, vcfg, key, expectValue)
 }
 
 func expectGetInt(t *testing.T, vcfg config.Conf, key string, expectValue int) {
 	t.Helper()
 
-	if v := vcfg.GetInt(key); v != expectValue {
-		t.Errorf("GetInt(): got '%d', want '%d'", v, expectValue)
-	}
+	conftest.ExpectInt(t, vcfg, key, expectValue)
 }
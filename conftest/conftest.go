@@ -0,0 +1,179 @@
+// Package conftest provides helpers for testing code that uses a config.Conf.
+package conftest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+	"github.com/na4ma4/config/internal/override"
+	"github.com/na4ma4/go-permbits"
+)
+
+//nolint:gochecknoglobals // test flag.
+var update = flag.Bool("conftest.update", false, "update the golden files compared by conftest.Golden")
+
+// ExpectGet fails the test if the value of the key is not deeply equal to want.
+func ExpectGet(t testing.TB, c config.Conf, key string, want interface{}) {
+	t.Helper()
+
+	if diff := cmp.Diff(c.Get(key), want); diff != "" {
+		t.Errorf("Get(\"%s\"): -got +want:\n%s", key, diff)
+	}
+}
+
+// ExpectBool fails the test if the value of the key is not want.
+func ExpectBool(t testing.TB, c config.Conf, key string, want bool) {
+	t.Helper()
+
+	if got := c.GetBool(key); got != want {
+		t.Errorf("GetBool(\"%s\"): got '%t', want '%t'", key, got, want)
+	}
+}
+
+// ExpectDuration fails the test if the value of the key is not want.
+func ExpectDuration(t testing.TB, c config.Conf, key string, want time.Duration) {
+	t.Helper()
+
+	if got := c.GetDuration(key); got != want {
+		t.Errorf("GetDuration(\"%s\"): got '%s', want '%s'", key, got, want)
+	}
+}
+
+// ExpectFloat64 fails the test if the value of the key is not want.
+func ExpectFloat64(t testing.TB, c config.Conf, key string, want float64) {
+	t.Helper()
+
+	if got := c.GetFloat64(key); got != want {
+		t.Errorf("GetFloat64(\"%s\"): got '%f', want '%f'", key, got, want)
+	}
+}
+
+// ExpectInt fails the test if the value of the key is not want.
+func ExpectInt(t testing.TB, c config.Conf, key string, want int) {
+	t.Helper()
+
+	if got := c.GetInt(key); got != want {
+		t.Errorf("GetInt(\"%s\"): got '%d', want '%d'", key, got, want)
+	}
+}
+
+// ExpectIntSlice fails the test if the value of the key is not want.
+func ExpectIntSlice(t testing.TB, c config.Conf, key string, want []int) {
+	t.Helper()
+
+	if diff := cmp.Diff(c.GetIntSlice(key), want); diff != "" {
+		t.Errorf("GetIntSlice(\"%s\"): -got +want:\n%s", key, diff)
+	}
+}
+
+// ExpectString fails the test if the value of the key is not want.
+func ExpectString(t testing.TB, c config.Conf, key, want string) {
+	t.Helper()
+
+	if got := c.GetString(key); got != want {
+		t.Errorf("GetString(\"%s\"): got '%s', want '%s'", key, got, want)
+	}
+}
+
+// ExpectStringSlice fails the test if the value of the key is not want.
+func ExpectStringSlice(t testing.TB, c config.Conf, key string, want []string) {
+	t.Helper()
+
+	if diff := cmp.Diff(c.GetStringSlice(key), want); diff != "" {
+		t.Errorf("GetStringSlice(\"%s\"): -got +want:\n%s", key, diff)
+	}
+}
+
+// ExpectSet fails the test if the key does not have a value.
+func ExpectSet(t testing.TB, c config.Conf, key string) {
+	t.Helper()
+
+	if !c.IsSet(key) {
+		t.Errorf("IsSet(\"%s\"): got 'false', want 'true'", key)
+	}
+}
+
+// ExpectNotSet fails the test if the key has a value.
+func ExpectNotSet(t testing.TB, c config.Conf, key string) {
+	t.Helper()
+
+	if c.IsSet(key) {
+		t.Errorf("IsSet(\"%s\"): got 'true', want 'false'", key)
+	}
+}
+
+// Override sets the key to value for the rest of the test, restoring the previous value
+// (or unsetting the key if it did not have one) when the test finishes.
+// For config.ViperConf the override layer is restored, so values from the config files still apply after a reload.
+func Override(t testing.TB, c config.Conf, key string, value interface{}) {
+	t.Helper()
+
+	switch restore, ok := override.Save(c, key); {
+	case ok:
+		t.Cleanup(restore)
+	case c.IsSet(key):
+		prev := c.Get(key)
+
+		t.Cleanup(func() {
+			c.Set(key, prev)
+		})
+	default:
+		t.Cleanup(func() {
+			c.Unset(key)
+		})
+	}
+
+	c.Set(key, value)
+}
+
+// Writer is implemented by Conf objects that can write their config, eg. config.ViperConf and config.MemoryConf.
+type Writer interface {
+	Write(out io.Writer) error
+}
+
+// Golden fails the test if the config written by c does not match testdata/<name>.golden.
+// Running the test with -conftest.update writes the golden file instead.
+func Golden(t testing.TB, c Writer, name string) {
+	t.Helper()
+
+	buf := bytes.NewBuffer(nil)
+	if err := c.Write(buf); err != nil {
+		t.Fatalf("Write(): error, got '%s', want 'nil'", err)
+	}
+
+	filename := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(filename), permbits.MustString("u=rwx,g=rx")); err != nil {
+			t.Fatalf("os.MkdirAll(): error, got '%s', want 'nil'", err)
+		}
+
+		if err := os.WriteFile(filename, buf.Bytes(), permbits.MustString("u=rw,g=r")); err != nil {
+			t.Fatalf("os.WriteFile(): error, got '%s', want 'nil'", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file \"%s\" does not exist, run the test with -conftest.update to create it", filename)
+	}
+
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(buf.String(), string(want)); diff != "" {
+		t.Errorf("Write(): output does not match \"%s\", -got +want:\n%s", filename, diff)
+	}
+}
@@ -0,0 +1,138 @@
+package conftest_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/config"
+	"github.com/na4ma4/config/conftest"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newTestConf() *config.MemoryConf {
+	return config.NewMemoryConf(map[string]interface{}{
+		"typing": map[string]interface{}{
+			"bool":        true,
+			"duration":    "10s",
+			"float64":     3.1415,
+			"int":         1337,
+			"intslice":    []int{100, 200, 50},
+			"string":      "foobarmoo",
+			"stringslice": []string{"one", "two", "three"},
+		},
+	})
+}
+
+func TestExpect_Pass(t *testing.T) {
+	c := newTestConf()
+
+	conftest.ExpectBool(t, c, "typing.bool", true)
+	conftest.ExpectDuration(t, c, "typing.duration", 10*time.Second)
+	conftest.ExpectFloat64(t, c, "typing.float64", 3.1415)
+	conftest.ExpectInt(t, c, "typing.int", 1337)
+	conftest.ExpectIntSlice(t, c, "typing.intslice", []int{100, 200, 50})
+	conftest.ExpectString(t, c, "typing.string", "foobarmoo")
+	conftest.ExpectStringSlice(t, c, "typing.stringslice", []string{"one", "two", "three"})
+	conftest.ExpectGet(t, c, "typing.string", "foobarmoo")
+	conftest.ExpectSet(t, c, "typing.int")
+	conftest.ExpectNotSet(t, c, "typing.missing")
+}
+
+func TestExpect_Fail(t *testing.T) {
+	c := newTestConf()
+	r := &recorder{TB: t}
+
+	conftest.ExpectBool(r, c, "typing.bool", false)
+	conftest.ExpectDuration(r, c, "typing.duration", time.Second)
+	conftest.ExpectFloat64(r, c, "typing.float64", 1)
+	conftest.ExpectInt(r, c, "typing.int", 1)
+	conftest.ExpectIntSlice(r, c, "typing.intslice", []int{1})
+	conftest.ExpectString(r, c, "typing.string", "wrong")
+	conftest.ExpectStringSlice(r, c, "typing.stringslice", []string{"wrong"})
+	conftest.ExpectGet(r, c, "typing.string", "wrong")
+	conftest.ExpectSet(r, c, "typing.missing")
+	conftest.ExpectNotSet(r, c, "typing.int")
+
+	if len(r.errors) != 10 {
+		t.Errorf("conftest.Expect*(): failures got '%d', want '10':\n%v", len(r.errors), r.errors)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	c := newTestConf()
+
+	t.Run("override", func(t *testing.T) {
+		conftest.Override(t, c, "typing.string", "overridden")
+		conftest.Override(t, c, "typing.new", "added")
+
+		conftest.ExpectString(t, c, "typing.string", "overridden")
+		conftest.ExpectString(t, c, "typing.new", "added")
+	})
+
+	conftest.ExpectString(t, c, "typing.string", "foobarmoo")
+	conftest.ExpectNotSet(t, c, "typing.new")
+}
+
+func TestOverrideReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+
+	writeFile := func(content string) {
+		t.Helper()
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile(): error, got '%s', want 'nil'", err)
+		}
+	}
+
+	writeFile("[cat]\na = 'a'\nb = 'b'\n")
+
+	c, err := config.New("test", config.WithConfigFiles(filename))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	c.Unset("cat.b")
+
+	t.Run("override", func(t *testing.T) {
+		conftest.Override(t, c, "cat.a", "x")
+		conftest.Override(t, c, "cat.b", "y")
+		conftest.Override(t, c, "cat.new", "z")
+
+		conftest.ExpectString(t, c, "cat.a", "x")
+		conftest.ExpectString(t, c, "cat.b", "y")
+		conftest.ExpectString(t, c, "cat.new", "z")
+	})
+
+	writeFile("[cat]\na = 'changed'\nb = 'b'\nnew = 'fromfile'\n")
+
+	if err = c.(*config.ViperConf).Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	conftest.ExpectString(t, c, "cat.a", "changed")
+	conftest.ExpectString(t, c, "cat.new", "fromfile")
+	conftest.ExpectNotSet(t, c, "cat.b")
+}
+
+func TestGolden(t *testing.T) {
+	c := config.NewMemoryConf(map[string]interface{}{
+		"category": map[string]interface{}{"test": "barfoo"},
+	})
+
+	conftest.Golden(t, c, "memory")
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// SliceMerge is the strategy used to merge a list in a conf.d drop-in with the list it overrides.
+type SliceMerge string
+
+const (
+	// SliceReplace replaces the list, this is the default.
+	SliceReplace SliceMerge = "replace"
+	// SliceAppend appends the items in the drop-in to the list.
+	SliceAppend SliceMerge = "append"
+	// SliceUniqueAppend appends the items in the drop-in that are not already in the list.
+	SliceUniqueAppend SliceMerge = "unique-append"
+)
+
+// DropInUnset is a value that removes the key (or section) from the configuration when set in a conf.d drop-in.
+const DropInUnset = "!unset"
+
+// dropInSection is the section of a drop-in holding the directives for merging it, it is not a setting.
+const dropInSection = "dropin"
+
+//nolint:gochecknoglobals // constant list.
+var dropInDirectives = []string{"slices", "unset", "replace", "append", "unique_append"}
+
+// ErrInvalidDropIn is returned when the [dropin] section of a conf.d drop-in is invalid.
+var ErrInvalidDropIn = errors.New("invalid drop-in directive")
+
+// parseSliceMerge returns the strategy named by val.
+func parseSliceMerge(val string) (SliceMerge, error) {
+	s := SliceMerge(strings.ReplaceAll(strings.ToLower(val), "_", "-"))
+	if !slices.Contains([]SliceMerge{SliceReplace, SliceAppend, SliceUniqueAppend}, s) {
+		return "", fmt.Errorf("%w: \"%s\" is not one of replace, append, unique-append", ErrInvalidDropIn, val)
+	}
+
+	return s, nil
+}
+
+// readDirectives removes the [dropin] section from the settings of a drop-in and applies it to the layer,
+// along with any DropInUnset values:
+//
+//	[dropin]
+//	unset = ["server.debug", "legacy"]    # keys or sections to remove
+//	replace = ["upstreams"]               # sections and lists that replace the existing value instead of merging
+//	slices = "append"                     # strategy for every list in the drop-in: replace, append or unique-append
+//	append = ["server.allowed_hosts"]     # lists that are appended to
+//	unique_append = ["server.tags"]       # lists that are appended to, skipping items already in the list
+func (l *layer) readDirectives() error {
+	markUnset(l.settings)
+
+	val, ok := l.settings[dropInSection]
+	if !ok {
+		return nil
+	}
+
+	delete(l.settings, dropInSection)
+
+	for key := range l.lines {
+		if key == dropInSection || strings.HasPrefix(key, dropInSection+keyDelimiter) {
+			delete(l.lines, key)
+		}
+	}
+
+	section, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: [%s] must be a section", ErrInvalidDropIn, dropInSection)
+	}
+
+	l.sliceKeys = map[string]SliceMerge{}
+
+	for name, val := range section {
+		if !slices.Contains(dropInDirectives, name) {
+			return fmt.Errorf("%w: unknown directive %s", ErrInvalidDropIn, name)
+		}
+
+		if name == "slices" {
+			s, err := parseSliceMerge(cast.ToString(val))
+			if err != nil {
+				return err
+			}
+
+			l.sliceMerge = s
+
+			continue
+		}
+
+		keys, err := cast.ToStringSliceE(listValue(val))
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a list of keys: %w", ErrInvalidDropIn, name, err)
+		}
+
+		for _, key := range keys {
+			key = strings.ToLower(key)
+
+			switch name {
+			case "unset":
+				setKey(l.settings, key, unsetValue{})
+			case "replace":
+				l.replace = append(l.replace, key)
+			case "append":
+				l.sliceKeys[key] = SliceAppend
+			case "unique_append":
+				l.sliceKeys[key] = SliceUniqueAppend
+			}
+		}
+	}
+
+	return nil
+}
+
+// markUnset replaces every DropInUnset value in m with an unset marker.
+func markUnset(m map[string]interface{}) {
+	for key, val := range m {
+		switch v := val.(type) {
+		case string:
+			if v == DropInUnset {
+				m[key] = unsetValue{}
+			}
+		case map[string]interface{}:
+			markUnset(v)
+		}
+	}
+}
+
+// replaces returns the section (or list) replaced by the layer that contains the key, if any.
+func (l layer) replaces(key string) (string, bool) {
+	for _, section := range l.replace {
+		if key != section && !strings.HasPrefix(key, section+keyDelimiter) {
+			continue
+		}
+
+		if _, ok := lookupKey(l.settings, section); ok {
+			return section, true
+		}
+	}
+
+	return "", false
+}
+
+// mergeInto merges the settings of the layer into dst, replacing sections and merging lists as directed.
+func (l layer) mergeInto(dst map[string]interface{}) {
+	for _, key := range l.replace {
+		if _, ok := lookupKey(l.settings, key); ok {
+			deleteKey(dst, key)
+		}
+	}
+
+	if len(l.sliceKeys) == 0 && cmp.Or(l.sliceMerge, SliceReplace) == SliceReplace {
+		mergeSettings(dst, l.settings)
+
+		return
+	}
+
+	type merge struct {
+		key      string
+		existing []interface{}
+		strategy SliceMerge
+	}
+
+	var merges []merge
+
+	for _, key := range flattenKeys(l.settings, "") {
+		strategy := l.sliceStrategy(key)
+		if strategy != SliceAppend && strategy != SliceUniqueAppend {
+			continue
+		}
+
+		val, _ := lookupKey(l.settings, key)
+		if _, ok := sliceItems(val); !ok {
+			continue
+		}
+
+		existing, _ := lookupKey(dst, key)
+		if items, ok := sliceItems(existing); ok {
+			merges = append(merges, merge{key: key, existing: items, strategy: strategy})
+		}
+	}
+
+	mergeSettings(dst, l.settings)
+
+	for _, m := range merges {
+		added, _ := lookupKey(dst, m.key)
+		items, _ := sliceItems(added)
+		out := slices.Clone(m.existing)
+
+		for _, item := range items {
+			if m.strategy == SliceUniqueAppend && slices.ContainsFunc(out, func(v interface{}) bool {
+				return reflect.DeepEqual(v, item)
+			}) {
+				continue
+			}
+
+			out = append(out, item)
+		}
+
+		setKey(dst, m.key, out)
+	}
+}
+
+// sliceStrategy returns the strategy used to merge the list at the key.
+func (l layer) sliceStrategy(key string) SliceMerge {
+	if _, ok := l.replaces(key); ok {
+		return SliceReplace
+	}
+
+	if s, ok := l.sliceKeys[key]; ok {
+		return s
+	}
+
+	return cmp.Or(l.sliceMerge, SliceReplace)
+}
+
+// sliceItems returns the items of val if it is a list.
+func sliceItems(val interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+
+	return items, true
+}
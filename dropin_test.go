@@ -0,0 +1,141 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+	"github.com/na4ma4/config/conftest"
+)
+
+// newDropInConf returns a config loaded from the base config file and the drop-ins, named in lexical order.
+func newDropInConf(t *testing.T, base string, dropins []string, opts ...config.Option) (config.Conf, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("os.Mkdir(): error, got '%s', want 'nil'", err)
+	}
+
+	filename := filepath.Join(dir, "test.toml")
+	writeTestFile(t, filename, base)
+
+	for i, dropin := range dropins {
+		writeTestFile(t, filepath.Join(confd, string(rune('a'+i))+".toml"), dropin)
+	}
+
+	return config.New("test", append([]config.Option{
+		config.WithConfigFiles(filename), config.WithConfD(confd),
+	}, opts...)...)
+}
+
+const dropInBase = `[server]
+address = "127.0.0.1:8080"
+debug = true
+allowed_hosts = ["a", "b"]
+tags = ["x", "y"]
+
+[upstreams]
+first = "http://first"
+second = "http://second"
+
+[legacy]
+option = "old"
+`
+
+func TestDropIn_Unset(t *testing.T) {
+	vcfg, err := newDropInConf(t, dropInBase, []string{
+		"[server]\ndebug = \"!unset\"\n",
+		"[dropin]\nunset = [\"legacy\", \"server.address\"]\n",
+	})
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	conftest.ExpectNotSet(t, vcfg, "server.debug")
+	conftest.ExpectNotSet(t, vcfg, "server.address")
+	conftest.ExpectNotSet(t, vcfg, "legacy")
+	conftest.ExpectNotSet(t, vcfg, "dropin")
+	conftest.ExpectStringSlice(t, vcfg, "server.allowed_hosts", []string{"a", "b"})
+
+	if o, ok := vcfg.(*config.ViperConf).Origin("server.debug"); ok || o.Kind != config.SourceUnset {
+		t.Errorf("config.Origin(): got '%s' (%t), want unset", o, ok)
+	}
+}
+
+func TestDropIn_Replace(t *testing.T) {
+	vcfg, err := newDropInConf(t, dropInBase, []string{
+		"[dropin]\nreplace = [\"upstreams\"]\n\n[upstreams]\nthird = \"http://third\"\n",
+		"[upstreams]\nfourth = \"http://fourth\"\n",
+	})
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(vcfg.Get("upstreams"), map[string]interface{}{
+		"third":  "http://third",
+		"fourth": "http://fourth",
+	}); diff != "" {
+		t.Errorf("config.Get(): upstreams -got +want:\n%s", diff)
+	}
+
+	if o, ok := vcfg.(*config.ViperConf).Origin("upstreams.first"); ok || o.Kind != config.SourceUnset {
+		t.Errorf("config.Origin(): got '%s' (%t), want unset", o, ok)
+	}
+}
+
+func TestDropIn_SliceMerge(t *testing.T) {
+	dropins := []string{
+		"[server]\nallowed_hosts = [\"b\", \"c\"]\ntags = [\"y\", \"z\"]\n",
+	}
+
+	tests := []struct {
+		name      string
+		dropins   []string
+		opts      []config.Option
+		wantHosts []string
+		wantTags  []string
+	}{
+		{"default replaces", dropins, nil, []string{"b", "c"}, []string{"y", "z"}},
+		{"option append", dropins, []config.Option{config.WithSliceMerge(config.SliceAppend)},
+			[]string{"a", "b", "b", "c"}, []string{"x", "y", "y", "z"}},
+		{"option unique-append", dropins, []config.Option{config.WithSliceMerge(config.SliceUniqueAppend)},
+			[]string{"a", "b", "c"}, []string{"x", "y", "z"}},
+		{"per file", []string{"[dropin]\nslices = \"unique-append\"\n" + dropins[0]}, nil,
+			[]string{"a", "b", "c"}, []string{"x", "y", "z"}},
+		{"per key", []string{"[dropin]\nappend = [\"server.allowed_hosts\"]\n" + dropins[0]}, nil,
+			[]string{"a", "b", "b", "c"}, []string{"y", "z"}},
+		{"replace overrides", []string{"[dropin]\nslices = \"append\"\nreplace = [\"server.tags\"]\n" + dropins[0]}, nil,
+			[]string{"a", "b", "b", "c"}, []string{"y", "z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcfg, err := newDropInConf(t, dropInBase, tt.dropins, tt.opts...)
+			if err != nil {
+				t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+			}
+
+			conftest.ExpectStringSlice(t, vcfg, "server.allowed_hosts", tt.wantHosts)
+			conftest.ExpectStringSlice(t, vcfg, "server.tags", tt.wantTags)
+		})
+	}
+}
+
+func TestDropIn_InvalidDirective(t *testing.T) {
+	for _, dropin := range []string{
+		"[dropin]\nslices = \"prepend\"\n",
+		"[dropin]\nremove = [\"server\"]\n",
+		"[dropin]\nremove = []\n",
+		"dropin = \"unset\"\n",
+	} {
+		if _, err := newDropInConf(t, dropInBase, []string{dropin}); !errors.Is(err, config.ErrInvalidDropIn) {
+			t.Errorf("config.New(): error, got '%v', want '%s'", err, config.ErrInvalidDropIn)
+		}
+	}
+}
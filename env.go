@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// envSectionSeparator separates the sections of a key in an environment variable name,
+// eg. TEST_PROJECT_SERVER__READ_TIMEOUT is server.read_timeout.
+const envSectionSeparator = "__"
+
+// envName returns the environment variable name for key, eg. server.address with a prefix of
+// "TEST_PROJECT" is TEST_PROJECT_SERVER_ADDRESS.
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, keyDelimiter, "_"))
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "_" + name
+}
+
+// envPrefix returns the environment variable prefix for a project name.
+func envPrefix(project string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(project))
+}
+
+// envLayer returns the settings overridden by environment variables.
+//
+// Variables map onto keys by replacing "__" with the key delimiter, eg. PREFIX_SERVER__READ_TIMEOUT is
+// server.read_timeout, which also works for keys that do not exist in base. Keys that exist in base can
+// also be set by replacing "_" with the key delimiter, eg. PREFIX_SERVER_ADDRESS is server.address.
+// Values for keys that hold a list in base are split on commas.
+func envLayer(prefix string, base map[string]interface{}) layer {
+	out := layer{kind: SourceEnv, settings: map[string]interface{}{}, sources: map[string]string{}}
+
+	for _, key := range flattenKeys(base, "") {
+		name := envName(prefix, key)
+		if val, ok := os.LookupEnv(name); ok {
+			setKey(out.settings, key, envValue(base, key, val))
+			out.sources[key] = name
+		}
+	}
+
+	if prefix == "" {
+		return out
+	}
+
+	for _, env := range os.Environ() {
+		fullName, val, _ := strings.Cut(env, "=")
+
+		name, ok := strings.CutPrefix(fullName, prefix+"_")
+		if !ok || !strings.Contains(name, envSectionSeparator) {
+			continue
+		}
+
+		key := strings.ToLower(strings.ReplaceAll(name, envSectionSeparator, keyDelimiter))
+		setKey(out.settings, key, envValue(base, key, val))
+		out.sources[key] = fullName
+	}
+
+	return out
+}
+
+// envValue returns the value of an environment variable for the key, splitting it on commas
+// if the key holds a list in base.
+func envValue(base map[string]interface{}, key, val string) interface{} {
+	existing, _ := lookupKey(base, key)
+
+	switch existing.(type) {
+	case []interface{}, []string, []int:
+		return splitList(val)
+	default:
+		return val
+	}
+}
+
+// splitList splits a comma separated list, trimming the space around each item.
+func splitList(val string) []string {
+	items := strings.Split(val, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+
+	return items
+}
+
+// listValue returns val split on commas if it is a comma separated string, otherwise val is returned as-is.
+func listValue(val interface{}) interface{} {
+	if s, ok := val.(string); ok && strings.Contains(s, ",") {
+		return splitList(s)
+	}
+
+	return val
+}
@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestEnv_KeyMapping(t *testing.T) {
+	t.Setenv("TEST_PROJECT_SERVER_ADDRESS", "10.0.0.1:8080")
+	t.Setenv("TEST_PROJECT_SERVER__READ_TIMEOUT", "30s")
+	t.Setenv("TEST_PROJECT_CATEGORY1_MISSING", "ignored")
+
+	vcfg, err := config.New("test-project", config.WithEnvPrefix(""))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "10.0.0.1:8080")
+	expectGetString(t, vcfg, "server.read_timeout", "30s")
+
+	if vcfg.IsSet("category1.missing") {
+		t.Error("config.IsSet(): category1.missing got 'true', want 'false'")
+	}
+}
+
+func TestEnv_Lists(t *testing.T) {
+	t.Setenv("TEST_PROJECT_TYPING_STRINGSLICE", "four, five,six")
+	t.Setenv("TEST_PROJECT_TYPING__INTSLICE", "1,2,3")
+
+	vcfg, err := config.New("test-project", config.WithEnvPrefix("test_project"))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(vcfg.GetStringSlice("typing.stringslice"), []string{"four", "five", "six"}); diff != "" {
+		t.Errorf("config.GetStringSlice(): -got +want:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(vcfg.GetIntSlice("typing.intslice"), []int{1, 2, 3}); diff != "" {
+		t.Errorf("config.GetIntSlice(): -got +want:\n%s", diff)
+	}
+}
+
+func TestEnv_PrecedenceAndSave(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[server]\naddress = '127.0.0.1:8080'\nport = 8080\n")
+
+	t.Setenv("TEST_SERVER_ADDRESS", "10.0.0.1:8080")
+	t.Setenv("TEST_SERVER_PORT", "9090")
+
+	vcfg := config.NewViperConfig("test", filename)
+	vcfg.(*config.ViperConf).SetEnvPrefix("")
+
+	expectGetString(t, vcfg, "server.address", "10.0.0.1:8080")
+	expectGetInt(t, vcfg, "server.port", 9090)
+
+	vcfg.SetInt("server.port", 7070)
+
+	expectGetInt(t, vcfg, "server.port", 7070)
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(string(b), "[server]\naddress = '127.0.0.1:8080'\nport = 7070\n"); diff != "" {
+		t.Errorf("config.Save(): config file -got +want:\n%s", diff)
+	}
+}
@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestFormat_MixedConfD(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("os.Mkdir(): error, got '%s', want 'nil'", err)
+	}
+
+	filename := filepath.Join(dir, "test.yaml")
+	writeTestFile(t, filename, "server:\n  address: 127.0.0.1:8080\n  port: 8080\n")
+	writeTestFile(t, filepath.Join(confd, "00-first.json"), `{"server": {"port": 9090}, "category": {"first": "json"}}`)
+	writeTestFile(t, filepath.Join(confd, "01-second.yml"), "category:\n  second: yaml\n")
+	writeTestFile(t, filepath.Join(confd, "02-third.toml"), "[category]\nthird = 'toml'\n")
+	writeTestFile(t, filepath.Join(confd, "README.md"), "not a config file\n")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename), config.WithConfD(confd))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+	expectGetInt(t, vcfg, "server.port", 9090)
+	expectGetString(t, vcfg, "category.first", "json")
+	expectGetString(t, vcfg, "category.second", "yaml")
+	expectGetString(t, vcfg, "category.third", "toml")
+
+	if o, _ := vcfg.(*config.ViperConf).Origin("server.address"); o.Line != 2 {
+		t.Errorf("config.Origin(): line got '%d', want '2'", o.Line)
+	}
+
+	if diff := cmp.Diff(vcfg.(*config.ViperConf).LoadReport().DropIns, []string{
+		filepath.Join(confd, "00-first.json"),
+		filepath.Join(confd, "01-second.yml"),
+		filepath.Join(confd, "02-third.toml"),
+	}); diff != "" {
+		t.Errorf("config.LoadReport(): drop-ins -got +want:\n%s", diff)
+	}
+}
+
+func TestFormat_SaveAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.yaml")
+	writeTestFile(t, filename, "server:\n  port: 8080\n")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg.SetString("server.address", "127.0.0.1:8080")
+
+	if err = vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(string(b), "server:\n    address: 127.0.0.1:8080\n    port: 8080\n"); diff != "" {
+		t.Errorf("config.Save(): config file -got +want:\n%s", diff)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = vcfg.(*config.ViperConf).WriteFormat(buf, "json"); err != nil {
+		t.Fatalf("config.WriteFormat(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(buf.String(), "{\n  \"server\": {\n    \"address\": \"127.0.0.1:8080\",\n    \"port\": 8080\n  }\n}"); diff != "" {
+		t.Errorf("config.WriteFormat(): -got +want:\n%s", diff)
+	}
+
+	vcfg.(*config.ViperConf).SetSaveFormat("toml")
+
+	buf.Reset()
+	if err = vcfg.(*config.ViperConf).Write(buf); err != nil {
+		t.Fatalf("config.Write(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(buf.String(), "\n[server]\n  address = \"127.0.0.1:8080\"\n  port = 8080\n"); diff != "" {
+		t.Errorf("config.Write(): -got +want:\n%s", diff)
+	}
+}
@@ -0,0 +1,11 @@
+// Package override gives conftest access to the runtime values of the config objects without adding to the
+// public API of the config package.
+package override
+
+// Save saves the runtime value of the key in c and returns a function that restores it, or false if c can not
+// restore it (the caller should fall back to Set and Unset). It is set by the config package.
+//
+//nolint:gochecknoglobals // set by the config package.
+var Save = func(_ interface{}, _ string) (func(), bool) {
+	return nil, false
+}
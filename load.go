@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// LoadReport describes the outcome of the most recent attempt to load the configuration files.
+type LoadReport struct {
+	// ConfigFile is the main config file that was loaded, empty if none was loaded.
+	ConfigFile string
+	// NotFound is true when no main config file could be found.
+	NotFound bool
+	// DropIns lists the conf.d files that were merged, in the order they were merged.
+	DropIns []string
+	// Errors lists every file that was found but could not be loaded.
+	Errors []*FileError
+}
+
+// Err returns every load error joined into a single error, or nil if all files were loaded.
+func (r LoadReport) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// clone returns a copy of the report that does not share slices with the original.
+func (r LoadReport) clone() LoadReport {
+	r.DropIns = slices.Clone(r.DropIns)
+	r.Errors = slices.Clone(r.Errors)
+
+	return r
+}
+
+// isNotFound reports whether err was caused by a config file not existing.
+func isNotFound(err error) bool {
+	var notFound viper.ConfigFileNotFoundError
+
+	return errors.Is(err, fs.ErrNotExist) || errors.As(err, &notFound)
+}
+
+// FileError is returned when a config file was found but could not be read or parsed.
+type FileError struct {
+	// Filename is the path of the file that failed to load.
+	Filename string
+	// Line and Column locate the parse error within the file, they are zero when unknown.
+	Line   int
+	Column int
+	// Err is the underlying error.
+	Err error
+}
+
+// newFileError wraps err for filename, extracting the position of the error if the parser provides one.
+func newFileError(filename string, err error) *FileError {
+	var fe *FileError
+	if errors.As(err, &fe) {
+		return fe
+	}
+
+	fe = &FileError{
+		Filename: filename,
+		Err:      err,
+	}
+
+	var perr interface{ Position() (int, int) }
+	if errors.As(err, &perr) {
+		fe.Line, fe.Column = perr.Position()
+	}
+
+	return fe
+}
+
+func (e *FileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid config file \"%s\" at line %d, column %d: %s", e.Filename, e.Line, e.Column, e.Err)
+	}
+
+	return fmt.Sprintf("unable to load config file \"%s\": %s", e.Filename, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/na4ma4/config"
+)
+
+func TestViper_LoadReport_NotFound(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing.toml")
+
+	vcfg := config.NewViperConfig("test", filename)
+
+	report := vcfg.(*config.ViperConf).LoadReport()
+	if !report.NotFound {
+		t.Error("config.LoadReport(): NotFound got 'false', want 'true'")
+	}
+
+	if err := report.Err(); err != nil {
+		t.Errorf("config.LoadReport(): error, got '%s', want 'nil'", err)
+	}
+}
+
+func TestViper_LoadReport_InvalidFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "invalid.toml")
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:8080\"\nport = \n")
+
+	vcfg := config.NewViperConfig("test", filename)
+
+	report := vcfg.(*config.ViperConf).LoadReport()
+	if report.NotFound {
+		t.Error("config.LoadReport(): NotFound got 'true', want 'false'")
+	}
+
+	if len(report.Errors) != 1 {
+		t.Fatalf("config.LoadReport(): errors got '%d', want '%d'", len(report.Errors), 1)
+	}
+
+	if fe := report.Errors[0]; fe.Filename != filename || fe.Line != 3 {
+		t.Errorf("config.LoadReport(): error location got '%s:%d', want '%s:%d'", fe.Filename, fe.Line, filename, 3)
+	}
+}
+
+func TestLoadViperConfig_FailFast(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.toml")
+	writeTestFile(t, invalid, "[server\n")
+
+	vcfg, err := config.LoadViperConfig("test", invalid, filepath.Join(dir, "fallback.toml"))
+	if vcfg != nil {
+		t.Error("config.LoadViperConfig(): conf, got non-nil, want 'nil'")
+	}
+
+	var fe *config.FileError
+	if !errors.As(err, &fe) {
+		t.Fatalf("config.LoadViperConfig(): error, got '%v', want config.FileError", err)
+	}
+
+	if fe.Filename != invalid {
+		t.Errorf("config.LoadViperConfig(): filename got '%s', want '%s'", fe.Filename, invalid)
+	}
+}
+
+func TestViperConfD_LoadReport_BestEffort(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	filename := filepath.Join(dir, "test.toml")
+
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("os.Mkdir(): error, got '%s', want 'nil'", err)
+	}
+
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:8080\"\n")
+	writeTestFile(t, filepath.Join(confd, "00-first.toml"), "[server]\nport = 8080\n")
+	writeTestFile(t, filepath.Join(confd, "01-broken.toml"), "[server\n")
+	writeTestFile(t, filepath.Join(confd, "02-third.toml"), "[server]\nport = 9090\n")
+
+	vcfg := config.NewViperConfD("test", confd, filename, filepath.Join(dir, "fallback.toml"))
+
+	expectGetInt(t, vcfg, "server.port", 9090)
+
+	report := vcfg.(*config.ViperConfD).LoadReport()
+	if report.ConfigFile != filename {
+		t.Errorf("config.LoadReport(): config file got '%s', want '%s'", report.ConfigFile, filename)
+	}
+
+	if len(report.DropIns) != 2 {
+		t.Errorf("config.LoadReport(): drop-ins got '%d', want '%d'", len(report.DropIns), 2)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].Filename != filepath.Join(confd, "01-broken.toml") {
+		t.Errorf("config.LoadReport(): errors got '%v', want '01-broken.toml'", report.Errors)
+	}
+
+	if _, err := config.LoadViperConfD("test", confd, filename, filepath.Join(dir, "fallback.toml")); err == nil {
+		t.Error("config.LoadViperConfD(): error, got 'nil', want error")
+	}
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+// MemoryConf is a Conf compatible configuration object held entirely in memory, without viper or
+// the filesystem, for tests and for embedding configuration in a binary.
+//
+// Values are converted by the getters with the same rules as ViperConf. Save does nothing unless a
+// writer has been set with SetSaveWriter. The zero value is an empty configuration.
+type MemoryConf struct {
+	lock    sync.Mutex
+	current atomic.Pointer[Snapshot]
+	out     io.Writer
+}
+
+// NewMemoryConf returns a MemoryConf holding a copy of the settings, nested keys are nested maps.
+func NewMemoryConf(settings map[string]interface{}) *MemoryConf {
+	m := &MemoryConf{}
+	m.current.Store(newSnapshot(copySettings(settings)))
+
+	return m
+}
+
+// NewMemoryConfFromString returns a MemoryConf holding the settings parsed from data in the format
+// (toml, yaml or json).
+func NewMemoryConfFromString(format, data string) (*MemoryConf, error) {
+	settings := map[string]interface{}{}
+
+	switch strings.ToLower(format) {
+	case "toml":
+		tree, err := toml.Load(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse config: %w", err)
+		}
+
+		settings = tree.ToMap()
+	case "yaml", "yml":
+		if err := yaml.Unmarshal([]byte(data), &settings); err != nil {
+			return nil, fmt.Errorf("unable to parse config: %w", err)
+		}
+	case "json":
+		if err := json.Unmarshal([]byte(data), &settings); err != nil {
+			return nil, fmt.Errorf("unable to parse config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unable to parse config: unsupported format \"%s\"", format)
+	}
+
+	return NewMemoryConf(settings), nil
+}
+
+// Snapshot returns the current configuration as a frozen, read-only Conf.
+func (m *MemoryConf) Snapshot() *Snapshot {
+	if s := m.current.Load(); s != nil {
+		return s
+	}
+
+	return emptySnapshot
+}
+
+// update replaces the settings with a modified copy.
+func (m *MemoryConf) update(fn func(settings map[string]interface{})) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	settings := copySettings(m.Snapshot().settings)
+	fn(settings)
+	m.current.Store(newSnapshot(settings))
+}
+
+// saveOverride returns a function that restores the key to its current value.
+func (m *MemoryConf) saveOverride(key string) func() {
+	saved := saveKey(m.Snapshot().settings, key)
+
+	return func() {
+		m.update(saved.restore)
+	}
+}
+
+// Get can retrieve any value given the key to use.
+// Get is case-insensitive for a key.
+func (m *MemoryConf) Get(key string) interface{} {
+	return m.Snapshot().Get(key)
+}
+
+// GetBool returns the value associated with the key as a boolean.
+func (m *MemoryConf) GetBool(key string) bool {
+	return m.Snapshot().GetBool(key)
+}
+
+// GetDuration returns the value associated with the key as a duration.
+func (m *MemoryConf) GetDuration(key string) time.Duration {
+	return m.Snapshot().GetDuration(key)
+}
+
+// GetFloat64 returns the value associated with the key as a float64.
+func (m *MemoryConf) GetFloat64(key string) float64 {
+	return m.Snapshot().GetFloat64(key)
+}
+
+// GetInt returns the value associated with the key as an int.
+func (m *MemoryConf) GetInt(key string) int {
+	return m.Snapshot().GetInt(key)
+}
+
+// GetIntSlice returns the value associated with the key as a slice of ints.
+// A comma separated string is split into its items.
+func (m *MemoryConf) GetIntSlice(key string) []int {
+	return m.Snapshot().GetIntSlice(key)
+}
+
+// GetString returns the value associated with the key as a string.
+func (m *MemoryConf) GetString(key string) string {
+	return m.Snapshot().GetString(key)
+}
+
+// GetStringSlice returns the value associated with the key as a slice of strings.
+// A comma separated string is split into its items.
+func (m *MemoryConf) GetStringSlice(key string) []string {
+	return m.Snapshot().GetStringSlice(key)
+}
+
+// Set sets the value for the key.
+func (m *MemoryConf) Set(key string, value interface{}) {
+	m.update(func(settings map[string]interface{}) {
+		setKey(settings, key, value)
+	})
+}
+
+// SetBool sets the value for the key.
+func (m *MemoryConf) SetBool(key string, value bool) {
+	m.Set(key, value)
+}
+
+// SetDuration sets the value for the key.
+func (m *MemoryConf) SetDuration(key string, value time.Duration) {
+	m.Set(key, value)
+}
+
+// SetFloat64 sets the value for the key.
+func (m *MemoryConf) SetFloat64(key string, value float64) {
+	m.Set(key, value)
+}
+
+// SetInt sets the value for the key.
+func (m *MemoryConf) SetInt(key string, value int) {
+	m.Set(key, value)
+}
+
+// SetIntSlice sets the value for the key.
+func (m *MemoryConf) SetIntSlice(key string, value []int) {
+	m.Set(key, value)
+}
+
+// SetString sets the value for the key.
+func (m *MemoryConf) SetString(key string, value string) {
+	m.Set(key, value)
+}
+
+// SetStringSlice sets the value for the key.
+func (m *MemoryConf) SetStringSlice(key string, value []string) {
+	m.Set(key, value)
+}
+
+// IsSet returns true if the key has a value.
+// IsSet is case-insensitive for a key.
+func (m *MemoryConf) IsSet(key string) bool {
+	return m.Snapshot().IsSet(key)
+}
+
+// Unset removes the key (or section) from the configuration.
+func (m *MemoryConf) Unset(key string) {
+	m.update(func(settings map[string]interface{}) {
+		unset := map[string]interface{}{}
+		setKey(unset, key, unsetValue{})
+		mergeSettings(settings, unset)
+	})
+}
+
+// AllKeys returns every key that has a value, sorted.
+func (m *MemoryConf) AllKeys() []string {
+	return m.Snapshot().AllKeys()
+}
+
+// AllSettings returns a copy of all settings as a map[string]interface{}.
+func (m *MemoryConf) AllSettings() map[string]interface{} {
+	return m.Snapshot().AllSettings()
+}
+
+// ZapConfig returns a zap logger configuration derived from the settings.
+func (m *MemoryConf) ZapConfig() zap.Config {
+	return m.Snapshot().ZapConfig()
+}
+
+// SlogOptions returns slog handler options derived from the settings, see NewSlogOptions.
+func (m *MemoryConf) SlogOptions() *slog.HandlerOptions {
+	return m.Snapshot().SlogOptions()
+}
+
+// SlogHandler returns a slog handler using SlogOptions, writing to the log.output_paths of the settings.
+func (m *MemoryConf) SlogHandler() (slog.Handler, error) {
+	return m.Snapshot().SlogHandler()
+}
+
+// SetSaveWriter sets the writer that Save writes the config to in TOML format,
+// a nil writer makes Save do nothing.
+func (m *MemoryConf) SetSaveWriter(out io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.out = out
+}
+
+// Save writes the config to the writer set by SetSaveWriter, if any.
+func (m *MemoryConf) Save() error {
+	m.lock.Lock()
+	out := m.out
+	m.lock.Unlock()
+
+	if out == nil {
+		return nil
+	}
+
+	return m.Write(out)
+}
+
+// Write writes the config to out in TOML format.
+func (m *MemoryConf) Write(out io.Writer) error {
+	t, err := toml.TreeFromMap(m.Snapshot().settings)
+	if err != nil {
+		return fmt.Errorf("unable to make tree from map: %w", err)
+	}
+
+	if _, err = io.WriteString(out, t.String()); err != nil {
+		return fmt.Errorf("unable to write config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestMemoryConf_FromMap(t *testing.T) {
+	var vcfg config.Conf = config.NewMemoryConf(map[string]interface{}{
+		"Server": map[string]interface{}{
+			"address": "127.0.0.1:8080",
+			"timeout": "30s",
+			"ports":   "80, 443",
+		},
+	})
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+
+	if got := vcfg.GetDuration("server.timeout"); got != 30*time.Second {
+		t.Errorf("config.GetDuration(): got '%s', want '30s'", got)
+	}
+
+	if diff := cmp.Diff(vcfg.GetIntSlice("server.ports"), []int{80, 443}); diff != "" {
+		t.Errorf("config.GetIntSlice(): -got +want:\n%s", diff)
+	}
+
+	vcfg.SetInt("server.port", 9090)
+	vcfg.Unset("server.timeout")
+
+	if diff := cmp.Diff(vcfg.AllKeys(), []string{"server.address", "server.port", "server.ports"}); diff != "" {
+		t.Errorf("config.AllKeys(): -got +want:\n%s", diff)
+	}
+}
+
+func TestMemoryConf_FromString(t *testing.T) {
+	for _, tt := range []struct {
+		format, data string
+	}{
+		{"toml", "[typing]\nint = 1337\nbool = true\nstringslice = ['one', 'two']\n"},
+		{"yaml", "typing:\n  int: 1337\n  bool: true\n  stringslice: [one, two]\n"},
+		{"json", `{"typing": {"int": 1337, "bool": true, "stringslice": ["one", "two"]}}`},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			vcfg, err := config.NewMemoryConfFromString(tt.format, tt.data)
+			if err != nil {
+				t.Fatalf("config.NewMemoryConfFromString(): error, got '%s', want 'nil'", err)
+			}
+
+			expectGetInt(t, vcfg, "typing.int", 1337)
+			expectGetString(t, vcfg, "typing.int", "1337")
+
+			if !vcfg.GetBool("typing.bool") {
+				t.Error("config.GetBool(): got 'false', want 'true'")
+			}
+
+			if diff := cmp.Diff(vcfg.GetStringSlice("typing.stringslice"), []string{"one", "two"}); diff != "" {
+				t.Errorf("config.GetStringSlice(): -got +want:\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := config.NewMemoryConfFromString("toml", "[broken"); err == nil {
+		t.Error("config.NewMemoryConfFromString(): error, got 'nil', want error")
+	}
+}
+
+func TestMemoryConf_Save(t *testing.T) {
+	vcfg := &config.MemoryConf{}
+	vcfg.SetString("category.test", "barfoo")
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	vcfg.SetSaveWriter(buf)
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(buf.String(), "\n[category]\n  test = \"barfoo\"\n"); diff != "" {
+		t.Errorf("config.Save(): -got +want:\n%s", diff)
+	}
+}
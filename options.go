@@ -0,0 +1,290 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Option configures the Conf returned by New.
+type Option func(*options)
+
+type options struct {
+	project            string
+	files              []string
+	searchPaths        []string
+	defaultSearchPaths bool
+	saveTarget         string
+	confdpath          string
+	format             string
+	saveFormat         string
+	envPrefix          string
+	envEnabled         bool
+	secretsDir         string
+	secretsEnabled     bool
+	failFast           bool
+	legacy             bool
+	fileMode           fs.FileMode
+	registry           *Registry
+	validate           bool
+	sliceMerge         SliceMerge
+}
+
+// WithConfigFiles adds config files that are tried in order before the search paths, the first one found is loaded.
+func WithConfigFiles(filename ...string) Option {
+	return func(o *options) {
+		o.files = append(o.files, filename...)
+	}
+}
+
+// WithSearchPaths adds directories that are searched in order for a "<project>.<format>" config file,
+// they are searched before the default search paths.
+func WithSearchPaths(path ...string) Option {
+	return func(o *options) {
+		o.searchPaths = append(o.searchPaths, path...)
+	}
+}
+
+// WithoutDefaultSearchPaths stops the default search paths (./artifacts, ./test, ./testdata, $HOME/.config,
+// /etc, /etc/<project>, /usr/local/<project>/etc, /run/secrets and .) from being searched.
+func WithoutDefaultSearchPaths() Option {
+	return func(o *options) {
+		o.defaultSearchPaths = false
+	}
+}
+
+// WithSaveTarget sets the file that Save writes to, regardless of which config file was loaded.
+func WithSaveTarget(filename string) Option {
+	return func(o *options) {
+		o.saveTarget = filename
+	}
+}
+
+// WithConfD merges the drop-in files in the conf.d directory over the main config file.
+func WithConfD(confdpath string) Option {
+	return func(o *options) {
+		o.confdpath = confdpath
+	}
+}
+
+// WithFormat sets the format of the main config file (toml, yaml or json) that is searched for, and used
+// for files without a recognised extension, the default is toml. Files with a .toml, .yaml, .yml or .json
+// extension are always read in the format matching their extension.
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = strings.ToLower(format)
+	}
+}
+
+// WithSaveFormat sets the format (toml, yaml or json) that Save and Write use, regardless of the file extension.
+func WithSaveFormat(format string) Option {
+	return func(o *options) {
+		o.saveFormat = strings.ToLower(format)
+	}
+}
+
+// WithEnvPrefix enables reading values from environment variables named with the prefix,
+// eg. a prefix of "MYAPP" maps MYAPP_SERVER__ADDRESS or MYAPP_SERVER_ADDRESS onto server.address.
+// If the prefix is empty, the project name is used.
+//
+// Environment variables take precedence over the config files but not over values set at runtime,
+// and are never written back by Save.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+		o.envEnabled = true
+	}
+}
+
+// WithSecretsDir enables reading secret files, each file in the directory sets the key matching its name,
+// eg. database.password, and an environment variable named for a key with a "_FILE" suffix names the file
+// holding its value, eg. DATABASE_PASSWORD_FILE. If the directory is empty, DefaultSecretsDir is used.
+//
+// Trailing newlines are trimmed, and files that can be written by group or others are refused.
+// Secrets take precedence over the config files and environment variables but not over values set at
+// runtime, and are never written back by Save.
+func WithSecretsDir(dir string) Option {
+	return func(o *options) {
+		o.secretsDir = dir
+		o.secretsEnabled = true
+	}
+}
+
+// WithFileMode sets the mode that Save creates the config file with, eg. 0600 for files holding credentials.
+// Without it, Save keeps the mode of the existing file.
+func WithFileMode(mode fs.FileMode) Option {
+	return func(o *options) {
+		o.fileMode = mode
+	}
+}
+
+// WithRegistry sets the registry of keys the configuration understands, the defaults of the registered keys
+// are used as the defaults of the configuration.
+func WithRegistry(r *Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithValidation validates the configuration against the registry set by WithRegistry after it is loaded
+// and after every reload, New returns a ValidationError if the configuration is invalid and Reload keeps
+// the existing configuration.
+func WithValidation() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// WithSliceMerge sets the strategy used to merge lists in conf.d drop-ins with the lists they override,
+// the default is SliceReplace. A drop-in can set its own strategy in its [dropin] section.
+func WithSliceMerge(strategy SliceMerge) Option {
+	return func(o *options) {
+		o.sliceMerge = strategy
+	}
+}
+
+// WithBestEffort skips config files that are found but can not be loaded instead of returning an error,
+// LoadReport describes any errors.
+func WithBestEffort() Option {
+	return func(o *options) {
+		o.failFast = false
+	}
+}
+
+// New returns a Conf compatible object configured by the supplied options.
+//
+// Unless WithBestEffort is supplied, an error is returned if any config file that is found can not be loaded.
+func New(project string, opts ...Option) (Conf, error) {
+	o := defaultOptions(project)
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v, err := loadViperConf(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func defaultOptions(project string) *options {
+	return &options{
+		project:            project,
+		defaultSearchPaths: true,
+		format:             "toml",
+		failFast:           true,
+	}
+}
+
+// legacyOptions returns the options matching the positional filename semantics of NewViperConfig and
+// NewViperConfD, where the first file found is loaded, the last filename is the fallback used by Save
+// and the default search paths are only used when no filenames are supplied.
+func legacyOptions(project, confdpath string, failFast bool, filename []string) *options {
+	o := defaultOptions(project)
+	o.files = filename
+	o.defaultSearchPaths = len(filename) == 0
+	o.confdpath = confdpath
+	o.failFast = failFast
+	o.legacy = true
+
+	return o
+}
+
+// skipConfD returns true for the legacy constructors when falling back to the last filename,
+// which has never merged the conf.d directory.
+func (o *options) skipConfD(report LoadReport) bool {
+	if !o.legacy || len(o.files) == 0 {
+		return false
+	}
+
+	return !slices.Contains(o.files[:len(o.files)-1], report.ConfigFile)
+}
+
+// candidates returns every config file that should be tried, in order.
+func (o *options) candidates() []string {
+	paths := slices.Clone(o.searchPaths)
+	if o.defaultSearchPaths {
+		paths = append(paths, defaultSearchPaths(o.project)...)
+	}
+
+	exts := []string{o.format}
+	if o.format == "yaml" {
+		exts = append(exts, "yml")
+	}
+
+	out := slices.Clone(o.files)
+
+	for _, path := range paths {
+		path = os.ExpandEnv(path)
+		if abspath, err := filepath.Abs(path); err == nil {
+			path = abspath
+		}
+
+		for _, ext := range exts {
+			out = append(out, filepath.Join(path, o.project+"."+ext))
+		}
+	}
+
+	return out
+}
+
+// readInConfig loads the first candidate config file that exists.
+func (o *options) readInConfig() (layer, LoadReport, error) {
+	var report LoadReport
+
+	for _, fname := range o.candidates() {
+		file, err := readConfigFile(fname, o.format)
+		if err == nil {
+			report.ConfigFile = fname
+
+			return file, report, nil
+		}
+
+		if isNotFound(err) {
+			continue
+		}
+
+		if o.failFast {
+			return layer{}, report, err
+		}
+
+		report.Errors = append(report.Errors, newFileError(fname, err))
+	}
+
+	report.NotFound = len(report.Errors) == 0
+
+	return layer{settings: map[string]interface{}{}}, report, nil
+}
+
+// filename returns the file that Save should write to.
+func (o *options) filename(report LoadReport) string {
+	switch {
+	case o.saveTarget != "":
+		return filepath.Clean(os.ExpandEnv(o.saveTarget))
+	case report.ConfigFile != "":
+		return report.ConfigFile
+	case len(o.files) > 0:
+		return o.files[len(o.files)-1]
+	default:
+		return o.project + "." + o.format
+	}
+}
+
+// defaultSearchPaths returns the directories searched for "<project>.toml" when no config file is specified.
+func defaultSearchPaths(project string) []string {
+	return []string{
+		"./artifacts",
+		"./test",
+		"./testdata",
+		"$HOME/.config",
+		"/etc",
+		"/etc/" + project,
+		"/usr/local/" + project + "/etc",
+		"/run/secrets",
+		".",
+	}
+}
@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/na4ma4/config"
+)
+
+func TestNew_DefaultSearchPaths(t *testing.T) {
+	vcfg, err := config.New("test-project")
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "category1.string", "foobar")
+}
+
+func TestNew_WithoutDefaultSearchPaths(t *testing.T) {
+	vcfg, err := config.New("test-project", config.WithoutDefaultSearchPaths())
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "category1.string", "")
+
+	if report := vcfg.(*config.ViperConf).LoadReport(); !report.NotFound {
+		t.Error("config.LoadReport(): NotFound got 'false', want 'true'")
+	}
+}
+
+func TestNew_WithSearchPathsAndConfD(t *testing.T) {
+	vcfg, err := config.New("test-project",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSearchPaths("testdata"),
+		config.WithConfD("testdata/conf.d"),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "category1.string", "foobar")
+	expectGetInt(t, vcfg, "category2.int", 8335)
+	expectGetString(t, vcfg, "category3.second", "foobar")
+}
+
+func TestNew_WithSaveTarget(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "saved.toml")
+
+	vcfg, err := config.New("test-project",
+		config.WithConfigFiles("testdata/test-project.toml"),
+		config.WithSaveTarget(target),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if err = vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	saved, err := config.New("saved", config.WithoutDefaultSearchPaths(), config.WithConfigFiles(target))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetInt(t, saved, "category1.int", 8008)
+}
+
+func TestNew_WithFormat(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "test.yml"), "server:\n  address: 127.0.0.1:8080\n")
+
+	vcfg, err := config.New("test",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSearchPaths(dir),
+		config.WithFormat("yaml"),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+}
+
+func TestNew_WithEnvPrefix(t *testing.T) {
+	t.Setenv("TEST_PROJECT_SERVER_ADDRESS", "10.0.0.1:8080")
+
+	vcfg, err := config.New("test-project", config.WithEnvPrefix(""))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "10.0.0.1:8080")
+}
+
+func TestNew_FailFast(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "test.toml"), "[server\n")
+
+	if _, err := config.New("test", config.WithoutDefaultSearchPaths(), config.WithSearchPaths(dir)); err == nil {
+		t.Error("config.New(): error, got 'nil', want error")
+	}
+
+	vcfg, err := config.New("test",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSearchPaths(dir),
+		config.WithBestEffort(),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if report := vcfg.(*config.ViperConf).LoadReport(); len(report.Errors) != 1 {
+		t.Errorf("config.LoadReport(): errors got '%d', want '%d'", len(report.Errors), 1)
+	}
+}
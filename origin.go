@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// SourceKind identifies the layer that a value came from.
+type SourceKind string
+
+const (
+	// SourceDefault is a value set by SetDefault.
+	SourceDefault SourceKind = "default"
+	// SourceFile is a value read from the main config file.
+	SourceFile SourceKind = "file"
+	// SourceDropIn is a value read from a file in the conf.d directory.
+	SourceDropIn SourceKind = "conf.d"
+	// SourceEnv is a value read from an environment variable.
+	SourceEnv SourceKind = "env"
+	// SourceSecret is a value read from a secret file.
+	SourceSecret SourceKind = "secret"
+	// SourceOverride is a value set at runtime.
+	SourceOverride SourceKind = "override"
+	// SourceUnset is a key removed at runtime by Unset, or by a conf.d drop-in.
+	SourceUnset SourceKind = "unset"
+)
+
+// Origin describes where a value came from.
+type Origin struct {
+	// Kind is the layer the value came from, it is empty if no layer has a value for the key.
+	Kind SourceKind
+	// Source is the file or environment variable the value was read from, empty for defaults and overrides.
+	Source string
+	// Line is the line of Source the value was set on, zero when unknown.
+	Line int
+	// Value is the value held by the layer.
+	Value interface{}
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Line > 0:
+		return fmt.Sprintf("%s %s:%d", o.Kind, o.Source, o.Line)
+	case o.Source != "":
+		return fmt.Sprintf("%s %s", o.Kind, o.Source)
+	default:
+		return string(o.Kind)
+	}
+}
+
+// Explanation describes how the effective value of a key was decided.
+type Explanation struct {
+	// Key is the key that was explained.
+	Key string
+	// Origin is the layer that provided the effective value.
+	Origin Origin
+	// Shadowed lists the values in lower layers that were overridden, from the highest precedence to the lowest.
+	Shadowed []Origin
+}
+
+// Explain returns where the effective value of the key came from and the values from lower layers that it
+// overrides. Explain is case-insensitive for a key.
+func (v *ViperConf) Explain(key string) Explanation {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	return explainKey(v.layers(true), key)
+}
+
+// Origin returns where the effective value of the key came from,
+// or false if the key does not have a value.
+func (v *ViperConf) Origin(key string) (Origin, bool) {
+	e := v.Explain(key)
+
+	return e.Origin, e.Origin.Kind != "" && e.Origin.Kind != SourceUnset
+}
+
+// AllOrigins returns where the effective value of every key came from.
+func (v *ViperConf) AllOrigins() map[string]Origin {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	layers := v.layers(true)
+	out := map[string]Origin{}
+
+	for _, key := range flattenKeys(v.snapshot().settings, "") {
+		out[key] = explainKey(layers, key).Origin
+	}
+
+	return out
+}
+
+// explainKey checks the layers from the highest precedence to the lowest for the key.
+func explainKey(layers []layer, key string) Explanation {
+	key = strings.ToLower(key)
+	e := Explanation{Key: key}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		val, found, unset := lookupOrigin(layers[i].settings, key)
+		if !found && !unset {
+			// A section replaced by the layer hides the key in lower layers.
+			if section, ok := layers[i].replaces(key); ok {
+				if e.Origin.Kind == "" {
+					e.Origin = layers[i].origin(section, nil)
+					e.Origin.Kind = SourceUnset
+				}
+
+				break
+			}
+
+			continue
+		}
+
+		o := layers[i].origin(key, val)
+		if unset {
+			o.Kind, o.Value = SourceUnset, nil
+		}
+
+		if e.Origin.Kind == "" {
+			e.Origin = o
+		} else if !unset {
+			e.Shadowed = append(e.Shadowed, o)
+		}
+	}
+
+	return e
+}
+
+// origin returns the Origin of the key within the layer.
+func (l layer) origin(key string, val interface{}) Origin {
+	o := Origin{
+		Kind:   l.kind,
+		Source: l.source,
+		Line:   l.lines[key],
+		Value:  copyValue(val),
+	}
+
+	if source, ok := l.sources[key]; ok {
+		o.Source = source
+	}
+
+	return o
+}
+
+// lookupOrigin returns the value at the nested key in m, and whether the key (or a section containing it)
+// has been unset.
+func lookupOrigin(m map[string]interface{}, key string) (interface{}, bool, bool) {
+	path := splitKey(key)
+
+	for i, section := range path {
+		val, ok := m[section]
+		if !ok {
+			return nil, false, false
+		}
+
+		if _, ok = val.(unsetValue); ok {
+			return nil, false, true
+		}
+
+		if i == len(path)-1 {
+			return val, true, false
+		}
+
+		if m, ok = val.(map[string]interface{}); !ok {
+			return nil, false, false
+		}
+	}
+
+	return nil, false, false
+}
+
+// keyLines returns the line each key is set on in the config file data, for the formats that record it.
+func keyLines(data []byte, format string) map[string]int {
+	lines := map[string]int{}
+
+	switch format {
+	case "toml":
+		if tree, err := toml.LoadBytes(data); err == nil {
+			tomlLines(tree, "", lines)
+		}
+	case "yaml", "yml":
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err == nil && len(doc.Content) > 0 {
+			yamlLines(doc.Content[0], "", lines)
+		}
+	}
+
+	return lines
+}
+
+// tomlLines records the line of every key in the tree.
+func tomlLines(tree *toml.Tree, prefix string, lines map[string]int) {
+	for _, name := range tree.Keys() {
+		key := joinKey(prefix, name)
+
+		if pos := tree.GetPositionPath([]string{name}); !pos.Invalid() {
+			lines[key] = pos.Line
+		}
+
+		if sub, ok := tree.GetPath([]string{name}).(*toml.Tree); ok {
+			tomlLines(sub, key, lines)
+		}
+	}
+}
+
+// yamlLines records the line of every key in the mapping node.
+func yamlLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := joinKey(prefix, node.Content[i].Value)
+		lines[key] = node.Content[i].Line
+
+		yamlLines(node.Content[i+1], key, lines)
+	}
+}
+
+// joinKey returns the lower-cased key for name within the prefix section.
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return strings.ToLower(name)
+	}
+
+	return prefix + keyDelimiter + strings.ToLower(name)
+}
@@ -0,0 +1,67 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestOrigin_Explain(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.toml")
+	writeTestFile(t, filename, "[category3]\n  first = 'main'\n  second = 'main'\n")
+
+	t.Setenv("TEST_CATEGORY3_FIRST", "env")
+
+	vcfg, err := config.New("test",
+		config.WithConfigFiles(filename),
+		config.WithConfD("testdata/conf.d"),
+		config.WithEnvPrefix(""),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg.(*config.ViperConf).SetDefault("category3.second", "default")
+	vcfg.SetString("category3.third", "override")
+
+	confd, _ := filepath.Abs("testdata/conf.d")
+
+	e := vcfg.(*config.ViperConf).Explain("Category3.Second")
+	if diff := cmp.Diff(e, config.Explanation{
+		Key:    "category3.second",
+		Origin: config.Origin{Kind: config.SourceDropIn, Source: filepath.Join(confd, "01-second.toml"), Line: 2, Value: "foobar"},
+		Shadowed: []config.Origin{
+			{Kind: config.SourceDropIn, Source: filepath.Join(confd, "00-first.toml"), Line: 3, Value: "bar"},
+			{Kind: config.SourceFile, Source: filename, Line: 3, Value: "main"},
+			{Kind: config.SourceDefault, Value: "default"},
+		},
+	}); diff != "" {
+		t.Errorf("config.Explain(): -got +want:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(vcfg.(*config.ViperConf).AllOrigins(), map[string]config.Origin{
+		"category3.first":  {Kind: config.SourceEnv, Source: "TEST_CATEGORY3_FIRST", Value: "env"},
+		"category3.second": {Kind: config.SourceDropIn, Source: filepath.Join(confd, "01-second.toml"), Line: 2, Value: "foobar"},
+		"category3.third":  {Kind: config.SourceOverride, Value: "override"},
+		"category2.int":    {Kind: config.SourceDropIn, Source: filepath.Join(confd, "test-confd.toml"), Line: 2, Value: int64(8335)},
+	}); diff != "" {
+		t.Errorf("config.AllOrigins(): -got +want:\n%s", diff)
+	}
+}
+
+func TestOrigin_Unset(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project", "testdata/test-project.toml")
+	vcfg.Unset("server.address")
+
+	if o, ok := vcfg.(*config.ViperConf).Origin("server.address"); ok || o.Kind != config.SourceUnset {
+		t.Errorf("config.Origin(): got '%v', '%t', want '%s', 'false'", o, ok, config.SourceUnset)
+	}
+
+	e := vcfg.(*config.ViperConf).Explain("server.address")
+	if len(e.Shadowed) != 1 || e.Shadowed[0].Value != "127.0.0.1:8080" || e.Shadowed[0].String() != "file testdata/test-project.toml:8" {
+		t.Errorf("config.Explain(): shadowed got '%v', want 'file testdata/test-project.toml:8'", e.Shadowed)
+	}
+}
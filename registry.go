@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyType is the type of value held by a registered key.
+type KeyType string
+
+const (
+	// TypeAny accepts any value.
+	TypeAny KeyType = ""
+	// TypeString is a string.
+	TypeString KeyType = "string"
+	// TypeBool is a boolean.
+	TypeBool KeyType = "bool"
+	// TypeInt is an integer.
+	TypeInt KeyType = "int"
+	// TypeFloat64 is a floating point number.
+	TypeFloat64 KeyType = "float64"
+	// TypeDuration is a duration, eg. "10s".
+	TypeDuration KeyType = "duration"
+	// TypeIntSlice is a list of integers.
+	TypeIntSlice KeyType = "[]int"
+	// TypeStringSlice is a list of strings.
+	TypeStringSlice KeyType = "[]string"
+	// TypeMap is a section of keys.
+	TypeMap KeyType = "map"
+)
+
+//nolint:gochecknoglobals // constant list.
+var keyTypes = []KeyType{
+	TypeAny, TypeString, TypeBool, TypeInt, TypeFloat64, TypeDuration, TypeIntSlice, TypeStringSlice, TypeMap,
+}
+
+var (
+	// ErrUnknownType is returned when a key is registered with a type that is not supported.
+	ErrUnknownType = errors.New("unknown key type")
+
+	// ErrDuplicateKey is returned when a key is registered more than once.
+	ErrDuplicateKey = errors.New("key is already registered")
+
+	// ErrInvalidKey is returned when a key is registered without a name.
+	ErrInvalidKey = errors.New("key has no name")
+)
+
+// check returns an error if val can not be converted to the type.
+//
+//nolint:cyclop // one case per supported type.
+func (t KeyType) check(val interface{}) error {
+	var err error
+
+	switch t {
+	case TypeAny:
+	case TypeString:
+		_, err = convertValue[string](val)
+	case TypeBool:
+		_, err = convertValue[bool](val)
+	case TypeInt:
+		_, err = convertValue[int](val)
+	case TypeFloat64:
+		_, err = convertValue[float64](val)
+	case TypeDuration:
+		_, err = convertValue[time.Duration](val)
+	case TypeIntSlice:
+		_, err = convertValue[[]int](val)
+	case TypeStringSlice:
+		_, err = convertValue[[]string](val)
+	case TypeMap:
+		_, err = convertValue[map[string]interface{}](val)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownType, t)
+	}
+
+	return err
+}
+
+// Key declares a configuration key understood by an application.
+type Key struct {
+	// Name is the fully qualified key, eg. server.address.
+	Name string
+	// Type is the type of value the key holds.
+	Type KeyType
+	// Default is the value used when the key is not set, nil for no default.
+	Default interface{}
+	// Description explains what the key does.
+	Description string
+	// Required is true if the key must be set.
+	Required bool
+	// Secret is true if the value is sensitive and should not be displayed.
+	Secret bool
+	// Rules are checked against the value of the key by Validate.
+	Rules []Rule
+}
+
+// Registry is a set of declared keys, it feeds the defaults of a ViperConf and can be queried by tooling.
+type Registry struct {
+	lock   sync.Mutex
+	keys   map[string]Key
+	checks []crossCheck
+}
+
+// NewRegistry returns a Registry with the keys registered, see Register.
+func NewRegistry(keys ...Key) (*Registry, error) {
+	r := &Registry{}
+
+	if err := r.Register(keys...); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MustRegistry returns a Registry with the keys registered, panicking if any of the keys are invalid.
+func MustRegistry(keys ...Key) *Registry {
+	r, err := NewRegistry(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Register declares the keys, returning an error if a key has no name, has already been registered,
+// has an unknown type or has a default that can not be converted to its type.
+// If any key is invalid, none of the keys are registered.
+// Keys are case-insensitive, the registered name is lower-cased.
+func (r *Registry) Register(keys ...Key) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	added := make(map[string]Key, len(keys))
+
+	for _, key := range keys {
+		key.Name = strings.ToLower(key.Name)
+
+		if key.Name == "" {
+			return ErrInvalidKey
+		}
+
+		if _, ok := r.keys[key.Name]; ok {
+			return fmt.Errorf("%w: \"%s\"", ErrDuplicateKey, key.Name)
+		}
+
+		if _, ok := added[key.Name]; ok {
+			return fmt.Errorf("%w: \"%s\"", ErrDuplicateKey, key.Name)
+		}
+
+		if !slices.Contains(keyTypes, key.Type) {
+			return fmt.Errorf("unable to register key \"%s\": %w: %s", key.Name, ErrUnknownType, key.Type)
+		}
+
+		if key.Default != nil {
+			if err := key.Type.check(key.Default); err != nil {
+				return fmt.Errorf("unable to register key \"%s\": invalid default: %w", key.Name, err)
+			}
+		}
+
+		added[key.Name] = key
+	}
+
+	if r.keys == nil {
+		r.keys = map[string]Key{}
+	}
+
+	maps.Copy(r.keys, added)
+
+	return nil
+}
+
+// Lookup returns the declaration of the key.
+func (r *Registry) Lookup(name string) (Key, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key, ok := r.keys[strings.ToLower(name)]
+
+	return key, ok
+}
+
+// Keys returns every registered key, sorted by name.
+func (r *Registry) Keys() []Key {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b Key) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return keys
+}
+
+// Required returns every key that must be set, sorted by name.
+func (r *Registry) Required() []Key {
+	return slices.DeleteFunc(r.Keys(), func(key Key) bool {
+		return !key.Required
+	})
+}
+
+// IsSecret returns true if the key, or the section containing it, is registered as secret.
+func (r *Registry) IsSecret(name string) bool {
+	path := splitKey(name)
+
+	for i := range path {
+		if key, ok := r.Lookup(strings.Join(path[:i+1], keyDelimiter)); ok && key.Secret {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaults returns the default value of every registered key that has one.
+func (r *Registry) defaults() map[string]interface{} {
+	out := map[string]interface{}{}
+
+	for _, key := range r.Keys() {
+		if key.Default != nil {
+			setKey(out, key.Name, key.Default)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func testRegistry(t *testing.T) *config.Registry {
+	t.Helper()
+
+	r, err := config.NewRegistry(
+		config.Key{Name: "server.address", Type: config.TypeString, Default: "127.0.0.1:8080", Description: "Listen address."},
+		config.Key{Name: "server.timeout", Type: config.TypeDuration, Default: "30s", Description: "Request timeout."},
+		config.Key{Name: "database.password", Type: config.TypeString, Required: true, Secret: true},
+		config.Key{Name: "Typing.IntSlice", Type: config.TypeIntSlice, Default: []int{1, 2}},
+	)
+	if err != nil {
+		t.Fatalf("config.NewRegistry(): error, got '%s', want 'nil'", err)
+	}
+
+	return r
+}
+
+func TestRegistry_Query(t *testing.T) {
+	r := testRegistry(t)
+
+	names := []string{}
+	for _, key := range r.Keys() {
+		names = append(names, key.Name)
+	}
+
+	if diff := cmp.Diff(names, []string{"database.password", "server.address", "server.timeout", "typing.intslice"}); diff != "" {
+		t.Errorf("config.Keys(): -got +want:\n%s", diff)
+	}
+
+	if key, ok := r.Lookup("SERVER.ADDRESS"); !ok || key.Description != "Listen address." {
+		t.Errorf("config.Lookup(): got '%v', '%t', want 'server.address', 'true'", key, ok)
+	}
+
+	if required := r.Required(); len(required) != 1 || required[0].Name != "database.password" {
+		t.Errorf("config.Required(): got '%v', want 'database.password'", required)
+	}
+
+	if !r.IsSecret("database.password") || r.IsSecret("server.address") {
+		t.Error("config.IsSecret(): got wrong result")
+	}
+}
+
+func TestRegistry_Invalid(t *testing.T) {
+	r := testRegistry(t)
+
+	if err := r.Register(config.Key{Name: "server.address"}); !errors.Is(err, config.ErrDuplicateKey) {
+		t.Errorf("config.Register(): error, got '%v', want '%s'", err, config.ErrDuplicateKey)
+	}
+
+	if err := r.Register(config.Key{Name: "server.port", Type: "port"}); !errors.Is(err, config.ErrUnknownType) {
+		t.Errorf("config.Register(): error, got '%v', want '%s'", err, config.ErrUnknownType)
+	}
+
+	if err := r.Register(config.Key{Name: "server.other"}, config.Key{Name: "server.port", Type: config.TypeInt, Default: "eighty"}); err == nil {
+		t.Error("config.Register(): error, got 'nil', want error")
+	}
+
+	if _, ok := r.Lookup("server.other"); ok {
+		t.Error("config.Lookup(): server.other registered by a failed Register")
+	}
+}
+
+func TestRegistry_Defaults(t *testing.T) {
+	vcfg, err := config.New("test-project",
+		config.WithConfigFiles("testdata/test-project.toml"),
+		config.WithRegistry(testRegistry(t)),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+	expectGetDuration(t, vcfg, "server.timeout", 30*time.Second)
+
+	if diff := cmp.Diff(vcfg.GetIntSlice("typing.intslice"), []int{100, 200, 50}); diff != "" {
+		t.Errorf("config.GetIntSlice(): -got +want:\n%s", diff)
+	}
+
+	if o, _ := vcfg.(*config.ViperConf).Origin("server.timeout"); o.Kind != config.SourceDefault {
+		t.Errorf("config.Origin(): got '%s', want '%s'", o.Kind, config.SourceDefault)
+	}
+}
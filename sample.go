@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrNoRegistry is returned when a sample config is requested without a registry of keys.
+var ErrNoRegistry = errors.New("no key registry")
+
+// ErrUnsupportedFormat is returned when a sample config is requested in a format other than toml or yaml.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// bareKey matches the keys that do not need quoting in a TOML inline table.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// GenerateSample writes a commented sample config file in the format (toml or yaml) describing every
+// registered key, with its description, type and default, grouped into sections.
+//
+// Keys are commented out with their default value, except for required keys which are left uncommented
+// so that they stand out. Defaults of secret keys are not written.
+func (r *Registry) GenerateSample(w io.Writer, format string) error {
+	buf := bytes.NewBuffer(nil)
+
+	// Every key in a section is written together, and keys without a section before the first section.
+	keys := r.Keys()
+	slices.SortStableFunc(keys, func(a, b Key) int {
+		pathA, pathB := splitKey(a.Name), splitKey(b.Name)
+
+		return cmp.Or(
+			slices.Compare(pathA[:len(pathA)-1], pathB[:len(pathB)-1]),
+			strings.Compare(pathA[len(pathA)-1], pathB[len(pathB)-1]),
+		)
+	})
+
+	format = strings.ToLower(format)
+
+	switch format {
+	case "toml":
+		writeSampleTOML(buf, keys)
+	case "yaml", "yml":
+		writeSampleYAML(buf, keys)
+	default:
+		return fmt.Errorf("unable to generate sample config: %w: %s", ErrUnsupportedFormat, format)
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("unable to write sample config: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSample writes a commented sample config file describing every key in the registry to filename,
+// in the format matching its extension (before any .example suffix). If filename is empty, the sample is
+// written next to the config file with an .example suffix, eg. /etc/<project>/<project>.toml.example.
+func (v *ViperConf) SaveSample(filename string) error {
+	v.lock.Lock()
+	registry := v.registry
+	filename = cmp.Or(filename, v.filename+".example")
+	format := formatForFile(strings.TrimSuffix(filename, ".example"), v.format)
+	v.lock.Unlock()
+
+	if registry == nil {
+		return ErrNoRegistry
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := registry.GenerateSample(buf, format); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filename, buf.Bytes(), 0); err != nil {
+		return fmt.Errorf("unable to write sample config: %w", err)
+	}
+
+	return nil
+}
+
+// writeSampleTOML writes the keys as TOML, with a table for each section.
+func writeSampleTOML(buf *bytes.Buffer, keys []Key) {
+	buf.WriteString("# Sample configuration, uncomment and change the values to override the defaults.\n")
+
+	section := ""
+
+	for _, key := range keys {
+		path := splitKey(key.Name)
+
+		if s := strings.Join(path[:len(path)-1], keyDelimiter); s != section {
+			section = s
+			fmt.Fprintf(buf, "\n[%s]\n", section)
+		}
+
+		buf.WriteString("\n")
+		writeSampleComment(buf, key, "")
+		writeSampleLine(buf, key, "", path[len(path)-1]+" = "+sampleValue(key, "toml"))
+	}
+}
+
+// writeSampleYAML writes the keys as YAML, with a nested mapping for each section.
+func writeSampleYAML(buf *bytes.Buffer, keys []Key) {
+	buf.WriteString("# Sample configuration, uncomment and change the values to override the defaults.\n")
+
+	var section []string
+
+	for _, key := range keys {
+		path := splitKey(key.Name)
+		parent := path[:len(path)-1]
+
+		common := 0
+		for common < len(section) && common < len(parent) && section[common] == parent[common] {
+			common++
+		}
+
+		for i := common; i < len(parent); i++ {
+			fmt.Fprintf(buf, "\n%s%s:\n", strings.Repeat("  ", i), parent[i])
+		}
+
+		section = parent
+		indent := strings.Repeat("  ", len(parent))
+
+		buf.WriteString("\n")
+		writeSampleComment(buf, key, indent)
+		writeSampleLine(buf, key, indent, path[len(path)-1]+": "+sampleValue(key, "yaml"))
+	}
+}
+
+// writeSampleComment writes the description, type and default of the key as comments.
+func writeSampleComment(buf *bytes.Buffer, key Key, indent string) {
+	for _, line := range strings.Split(strings.TrimSpace(key.Description), "\n") {
+		if line != "" {
+			fmt.Fprintf(buf, "%s# %s\n", indent, strings.TrimSpace(line))
+		}
+	}
+
+	info := []string{"type: " + string(cmp.Or(key.Type, "any"))}
+
+	switch {
+	case key.Secret:
+		info = append(info, "secret")
+	case key.Default != nil:
+		info = append(info, "default: "+sampleValue(key, "yaml"))
+	}
+
+	if key.Required {
+		info = append(info, "required")
+	}
+
+	fmt.Fprintf(buf, "%s# (%s)\n", indent, strings.Join(info, ", "))
+}
+
+// writeSampleLine writes the key and its value, commented out unless the key is required.
+func writeSampleLine(buf *bytes.Buffer, key Key, indent, line string) {
+	if key.Required {
+		fmt.Fprintf(buf, "%s%s\n", indent, line)
+
+		return
+	}
+
+	fmt.Fprintf(buf, "%s# %s\n", indent, line)
+}
+
+// sampleValue returns the default of the key (or the zero value of its type) formatted for the config format.
+func sampleValue(key Key, format string) string {
+	val := key.Default
+	if val == nil || key.Secret {
+		val = zeroValue(key.Type)
+	}
+
+	return formatSampleValue(copyValue(val), format)
+}
+
+// zeroValue returns the zero value of the type.
+func zeroValue(t KeyType) interface{} {
+	switch t {
+	case TypeBool:
+		return false
+	case TypeInt:
+		return 0
+	case TypeFloat64:
+		return 0.0
+	case TypeDuration:
+		return "0s"
+	case TypeIntSlice:
+		return []int{}
+	case TypeStringSlice:
+		return []string{}
+	case TypeMap:
+		return map[string]interface{}{}
+	default:
+		return ""
+	}
+}
+
+// formatSampleValue formats val as an inline value, JSON scalars and arrays are valid in both TOML and YAML.
+func formatSampleValue(val interface{}, format string) string {
+	switch v := val.(type) {
+	case time.Duration:
+		val = v.String()
+	case map[string]interface{}:
+		if format == "toml" {
+			return formatInlineTable(v)
+		}
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%q", fmt.Sprint(val))
+	}
+
+	return string(b)
+}
+
+// formatInlineTable formats m as a TOML inline table.
+func formatInlineTable(m map[string]interface{}) string {
+	if len(m) == 0 {
+		return "{}"
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	items := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		name := key
+		if !bareKey.MatchString(key) {
+			name = formatSampleValue(key, "toml")
+		}
+
+		items = append(items, name+" = "+formatSampleValue(m[key], "toml"))
+	}
+
+	return "{ " + strings.Join(items, ", ") + " }"
+}
@@ -0,0 +1,125 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func sampleRegistry(t *testing.T) *config.Registry {
+	t.Helper()
+
+	r := testRegistry(t)
+
+	if err := r.Register(
+		config.Key{Name: "debug", Type: config.TypeBool, Default: false, Description: "Enable debug logging."},
+		config.Key{Name: "server.tls.cert", Type: config.TypeString, Description: "Certificate file."},
+		config.Key{Name: "server.workers", Type: config.TypeInt, Default: 4},
+	); err != nil {
+		t.Fatalf("config.Register(): error, got '%s', want 'nil'", err)
+	}
+
+	return r
+}
+
+func TestGenerateSample_TOML(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	if err := sampleRegistry(t).GenerateSample(buf, "toml"); err != nil {
+		t.Fatalf("config.GenerateSample(): error, got '%s', want 'nil'", err)
+	}
+
+	expected := `# Sample configuration, uncomment and change the values to override the defaults.
+
+# Enable debug logging.
+# (type: bool, default: false)
+# debug = false
+
+[database]
+
+# (type: string, secret, required)
+password = ""
+
+[server]
+
+# Listen address.
+# (type: string, default: "127.0.0.1:8080")
+# address = "127.0.0.1:8080"
+
+# Request timeout.
+# (type: duration, default: "30s")
+# timeout = "30s"
+
+# (type: int, default: 4)
+# workers = 4
+
+[server.tls]
+
+# Certificate file.
+# (type: string)
+# cert = ""
+
+[typing]
+
+# (type: []int, default: [1,2])
+# intslice = [1,2]
+`
+
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("config.GenerateSample(): -got +want:\n%s", diff)
+	}
+
+	vcfg, err := config.NewMemoryConfFromString("toml", buf.String())
+	if err != nil {
+		t.Fatalf("config.NewMemoryConfFromString(): error, got '%s', want 'nil'", err)
+	}
+
+	if !vcfg.IsSet("database.password") || vcfg.IsSet("server.address") {
+		t.Errorf("config.IsSet(): only required keys should be set:\n%s", buf)
+	}
+}
+
+func TestGenerateSample_YAML(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	if err := sampleRegistry(t).GenerateSample(buf, "yaml"); err != nil {
+		t.Fatalf("config.GenerateSample(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg, err := config.NewMemoryConfFromString("yaml", buf.String())
+	if err != nil {
+		t.Fatalf("config.NewMemoryConfFromString(): error, got '%s', want 'nil'\n%s", err, buf)
+	}
+
+	expectGetString(t, vcfg, "database.password", "")
+
+	if !vcfg.IsSet("database.password") {
+		t.Errorf("config.IsSet(): database.password got 'false', want 'true'\n%s", buf)
+	}
+}
+
+func TestSaveSample(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.yaml")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename), config.WithRegistry(sampleRegistry(t)))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if err = vcfg.(*config.ViperConf).SaveSample(""); err != nil {
+		t.Fatalf("config.SaveSample(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename + ".example")
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if !bytes.Contains(b, []byte("\n  tls:\n")) {
+		t.Errorf("config.SaveSample(): expected YAML sample, got:\n%s", b)
+	}
+}
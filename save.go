@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/na4ma4/go-permbits"
+	"github.com/spf13/viper"
+)
+
+// encodeSettings renders the settings in the config format, eg. toml, yaml or json.
+func encodeSettings(settings map[string]interface{}, format string) ([]byte, error) {
+	out := viper.New()
+	out.SetConfigType(format)
+	_ = out.MergeConfigMap(copySettings(settings))
+
+	buf := bytes.NewBuffer(nil)
+	if err := out.WriteConfigTo(buf); err != nil {
+		return nil, fmt.Errorf("unable to encode config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// configExts are the extensions of the config files that are read from the conf.d directory.
+var configExts = []string{"toml", "yaml", "yml", "json"}
+
+// isConfigFile returns true if filename has the extension of a config format read from the conf.d directory.
+func isConfigFile(filename string) bool {
+	return slices.Contains(configExts, strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")))
+}
+
+// formatForFile returns the config format for filename based on its extension, falling back to format.
+func formatForFile(filename, format string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if slices.Contains(viper.SupportedExts, ext) {
+		return ext
+	}
+
+	return format
+}
+
+// writeFileAtomic replaces filename with data so that readers see either the old or the new contents.
+//
+// The data is written to a temporary file in the same directory, synced, given the mode and ownership
+// of the existing file (or mode if it is non-zero), renamed over filename and the directory is synced.
+func writeFileAtomic(filename string, data []byte, mode fs.FileMode) error {
+	// Replace the target of a symlink rather than the symlink itself.
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+
+	dir := filepath.Dir(filename)
+
+	if err := os.MkdirAll(dir, permbits.MustString("u=rwx,g=rx")); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+
+	existing, statErr := os.Stat(filename)
+	if statErr != nil && !errors.Is(statErr, fs.ErrNotExist) {
+		return fmt.Errorf("unable to stat config file: %w", statErr)
+	}
+
+	if mode == 0 {
+		mode = permbits.MustString("u=rw,g=r")
+		if existing != nil {
+			mode = existing.Mode().Perm()
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file: %w", err)
+	}
+
+	renamed := false
+
+	defer func() {
+		if !renamed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("unable to write temporary file: %w", err)
+	}
+
+	if err = tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("unable to set file mode: %w", err)
+	}
+
+	if existing != nil {
+		// Preserving ownership is best-effort, it is only possible when running as root or the owner.
+		_ = chownLike(tmp, existing)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("unable to sync temporary file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close temporary file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("unable to replace config file: %w", err)
+	}
+
+	renamed = true
+
+	if err = syncDir(dir); err != nil {
+		return fmt.Errorf("unable to sync directory: %w", err)
+	}
+
+	return nil
+}
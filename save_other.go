@@ -0,0 +1,18 @@
+//go:build !unix
+
+package config
+
+import (
+	"io/fs"
+	"os"
+)
+
+// chownLike is a no-op on platforms without unix file ownership.
+func chownLike(_ *os.File, _ fs.FileInfo) error {
+	return nil
+}
+
+// syncDir is a no-op on platforms where directories can not be synced.
+func syncDir(_ string) error {
+	return nil
+}
@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/na4ma4/config"
+)
+
+func expectFileMode(t *testing.T, filename string, expectMode os.FileMode) {
+	t.Helper()
+
+	st, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("os.Stat(): error, got '%s', want 'nil'", err)
+	}
+
+	if mode := st.Mode().Perm(); mode != expectMode {
+		t.Errorf("config.Save(): file mode got '%s', want '%s'", mode, expectMode)
+	}
+}
+
+func TestViper_SaveKeepsFileMode(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.toml")
+	writeTestFile(t, filename, "[category]\ntest = 'foobar'\n")
+
+	if err := os.Chmod(filename, 0o604); err != nil {
+		t.Fatalf("os.Chmod(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg := config.NewViperConfig("test", filename)
+	vcfg.SetString("category.test", "barfoo")
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	expectFileMode(t, filename, 0o604)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir(): error, got '%s', want 'nil'", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("config.Save(): directory entries got '%d', want '%d'", len(entries), 1)
+	}
+
+	expectGetString(t, config.NewViperConfig("test", filename), "category.test", "barfoo")
+}
+
+func TestViper_SaveWithFileMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secrets", "test.toml")
+
+	vcfg, err := config.New("test",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSaveTarget(filename),
+		config.WithFileMode(0o600),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg.SetString("database.password", "hunter2")
+
+	if err = vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	expectFileMode(t, filename, 0o600)
+}
+
+func TestViper_SaveThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.toml")
+	link := filepath.Join(dir, "link.toml")
+	writeTestFile(t, target, "")
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("os.Symlink(): error, got '%s', want 'nil'", err)
+	}
+
+	vcfg := config.NewViperConfig("test", link)
+	vcfg.SetString("category.test", "barfoo")
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	if st, err := os.Lstat(link); err != nil || st.Mode()&os.ModeSymlink == 0 {
+		t.Error("config.Save(): symlink was replaced")
+	}
+
+	expectGetString(t, config.NewViperConfig("test", target), "category.test", "barfoo")
+}
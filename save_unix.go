@@ -0,0 +1,33 @@
+//go:build unix
+
+package config
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// chownLike sets the owner and group of f to those of the existing file.
+func chownLike(f *os.File, existing fs.FileInfo) error {
+	st, ok := existing.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return f.Chown(int(st.Uid), int(st.Gid))
+}
+
+// syncDir flushes the directory entry so a rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = d.Close()
+	}()
+
+	return d.Sync()
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultSecretsDir is the directory Docker and Kubernetes conventionally mount secrets in.
+const DefaultSecretsDir = "/run/secrets"
+
+const (
+	// secretFileSuffix is appended to the environment variable name of a key to name a file holding its value,
+	// eg. DATABASE_PASSWORD_FILE.
+	secretFileSuffix = "_FILE"
+
+	// maxSecretSize is the largest secret file that will be read.
+	maxSecretSize = 1 << 20
+)
+
+var (
+	// ErrSecretNotRegular is returned when a secret is not a regular file.
+	ErrSecretNotRegular = errors.New("secret is not a regular file")
+
+	// ErrSecretWritable is returned when a secret file can be written by users other than its owner.
+	ErrSecretWritable = errors.New("secret file is writable by group or others")
+
+	// ErrSecretTooLarge is returned when a secret file is larger than the maximum secret size.
+	ErrSecretTooLarge = errors.New("secret file is too large")
+)
+
+// readSecrets returns the settings read from secret files, along with any secret files that could not be read.
+//
+// Every file in dir is read into the key matching its name, eg. /run/secrets/database.password is
+// database.password. For keys that exist in base, an environment variable named for the key with a "_FILE"
+// suffix names the file holding its value, eg. DATABASE_PASSWORD_FILE (prefixed with the environment prefix,
+// if set), and with an environment prefix, PREFIX_DATABASE__PASSWORD_FILE also works for keys not in base.
+func readSecrets(dir, prefix string, base map[string]interface{}) (layer, []*FileError) {
+	var (
+		out  = layer{kind: SourceSecret, settings: map[string]interface{}{}, sources: map[string]string{}}
+		errs []*FileError
+	)
+
+	read := func(key, filename string) {
+		val, err := readSecretFile(filename)
+		if err != nil {
+			errs = append(errs, newFileError(filename, err))
+
+			return
+		}
+
+		setKey(out.settings, key, val)
+		out.sources[key] = filename
+	}
+
+	if dir != "" {
+		// A missing secrets directory just means there are no secrets.
+		entries, _ := os.ReadDir(dir)
+
+		for _, entry := range entries {
+			name := entry.Name()
+
+			// Skip hidden entries (eg. the ..data links Kubernetes creates) and whole config files.
+			ext := strings.TrimPrefix(filepath.Ext(name), ".")
+			if strings.HasPrefix(name, ".") || entry.IsDir() || slices.Contains(viper.SupportedExts, ext) {
+				continue
+			}
+
+			read(strings.ToLower(name), filepath.Join(dir, name))
+		}
+	}
+
+	for _, key := range flattenKeys(base, "") {
+		if filename, ok := os.LookupEnv(envName(prefix, key) + secretFileSuffix); ok {
+			read(key, filename)
+		}
+	}
+
+	if prefix != "" {
+		for _, env := range os.Environ() {
+			name, filename, _ := strings.Cut(env, "=")
+
+			name, ok := strings.CutPrefix(name, prefix+"_")
+			if !ok || !strings.Contains(name, envSectionSeparator) {
+				continue
+			}
+
+			if name, ok = strings.CutSuffix(name, secretFileSuffix); ok {
+				read(strings.ToLower(strings.ReplaceAll(name, envSectionSeparator, keyDelimiter)), filename)
+			}
+		}
+	}
+
+	return out, errs
+}
+
+// readSecretFile returns the contents of a secret file without trailing newlines,
+// refusing files that are not regular files or that can be modified by anyone but their owner.
+func readSecretFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("unable to open secret: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	st, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("unable to stat secret: %w", err)
+	}
+
+	if !st.Mode().IsRegular() {
+		return "", ErrSecretNotRegular
+	}
+
+	if st.Mode().Perm()&0o022 != 0 {
+		return "", ErrSecretWritable
+	}
+
+	if st.Size() > maxSecretSize {
+		return "", ErrSecretTooLarge
+	}
+
+	b, err := io.ReadAll(io.LimitReader(f, maxSecretSize))
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret: %w", err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
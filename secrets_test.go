@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestSecrets_Dir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "database.password"), "s3cret\n")
+	writeTestFile(t, filepath.Join(dir, "api_token"), "token\r\n")
+	writeTestFile(t, filepath.Join(dir, "test.toml"), "ignored = true\n")
+
+	vcfg, err := config.New("test",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSecretsDir(dir),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "database.password", "s3cret")
+	expectGetString(t, vcfg, "api_token", "token")
+
+	if vcfg.IsSet("test.toml") {
+		t.Error("config.IsSet(): test.toml got 'true', want 'false'")
+	}
+}
+
+func TestSecrets_FileEnv(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.toml")
+	writeTestFile(t, filename, "[database]\npassword = 'from-file'\n")
+	writeTestFile(t, filepath.Join(dir, "password"), "from-secret\n")
+	writeTestFile(t, filepath.Join(dir, "token"), "from-nested\n")
+
+	t.Setenv("DATABASE_PASSWORD_FILE", filepath.Join(dir, "password"))
+
+	vcfg := config.NewViperConfig("test", filename)
+	vcfg.(*config.ViperConf).SetSecretsDir(filepath.Join(dir, "missing"))
+
+	expectGetString(t, vcfg, "database.password", "from-secret")
+
+	t.Setenv("TEST_API__TOKEN_FILE", filepath.Join(dir, "token"))
+	vcfg.(*config.ViperConf).SetEnvPrefix("")
+
+	expectGetString(t, vcfg, "api.token", "from-nested")
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(string(b), "[database]\npassword = 'from-file'\n"); diff != "" {
+		t.Errorf("config.Save(): config file -got +want:\n%s", diff)
+	}
+}
+
+func TestSecrets_Permissions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "database.password"), "s3cret\n")
+
+	if err := os.Chmod(filepath.Join(dir, "database.password"), 0o666); err != nil {
+		t.Fatalf("os.Chmod(): error, got '%s', want 'nil'", err)
+	}
+
+	if _, err := config.New("test", config.WithoutDefaultSearchPaths(), config.WithSecretsDir(dir)); !errors.Is(err, config.ErrSecretWritable) {
+		t.Errorf("config.New(): error, got '%v', want '%s'", err, config.ErrSecretWritable)
+	}
+
+	vcfg, err := config.New("test",
+		config.WithoutDefaultSearchPaths(),
+		config.WithSecretsDir(dir),
+		config.WithBestEffort(),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	if vcfg.IsSet("database.password") {
+		t.Error("config.IsSet(): database.password got 'true', want 'false'")
+	}
+
+	report := vcfg.(*config.ViperConf).LoadReport()
+	if len(report.Errors) != 1 || !errors.Is(report.Errors[0], config.ErrSecretWritable) {
+		t.Errorf("config.LoadReport(): errors got '%v', want '%s'", report.Errors, config.ErrSecretWritable)
+	}
+}
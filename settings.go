@@ -0,0 +1,282 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// keyDelimiter separates the sections of a nested key.
+const keyDelimiter = "."
+
+// layer is a single source of settings, layers are merged in order with later layers taking precedence.
+type layer struct {
+	kind SourceKind
+	// source is the file the settings were read from, empty for layers that are not backed by a file.
+	source   string
+	settings map[string]interface{}
+	// sources names the source of each key for layers where it differs per key, eg. the environment variable.
+	sources map[string]string
+	// lines maps each key to the line it was set on in the source file, where the format provides one.
+	lines map[string]int
+	// replace lists the sections and lists that replace the existing value rather than being merged into it.
+	replace []string
+	// sliceMerge is the strategy for merging lists, sliceKeys overrides it for individual keys.
+	sliceMerge SliceMerge
+	sliceKeys  map[string]SliceMerge
+}
+
+// splitKey returns the lower-cased path of a nested key.
+func splitKey(key string) []string {
+	return strings.Split(strings.ToLower(key), keyDelimiter)
+}
+
+// lookupKey returns the value at the nested key in m.
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	path := splitKey(key)
+
+	for i, section := range path {
+		val, ok := m[section]
+		if !ok {
+			return nil, false
+		}
+
+		if i == len(path)-1 {
+			return val, true
+		}
+
+		if m, ok = val.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// setKey sets the value at the nested key in m, creating (or replacing) any intermediate sections.
+func setKey(m map[string]interface{}, key string, value interface{}) {
+	path := splitKey(key)
+
+	for _, section := range path[:len(path)-1] {
+		next, ok := m[section].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[section] = next
+		}
+
+		m = next
+	}
+
+	m[path[len(path)-1]] = copyValue(value)
+}
+
+// deleteKey removes the nested key from m.
+func deleteKey(m map[string]interface{}, key string) {
+	path := splitKey(key)
+
+	for _, section := range path[:len(path)-1] {
+		next, ok := m[section].(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		m = next
+	}
+
+	delete(m, path[len(path)-1])
+}
+
+// pruneKey removes the nested key from m, along with any sections left empty by removing it.
+func pruneKey(m map[string]interface{}, key string) {
+	path := splitKey(key)
+	if len(path) == 1 {
+		delete(m, path[0])
+
+		return
+	}
+
+	if next, ok := m[path[0]].(map[string]interface{}); ok {
+		pruneKey(next, strings.Join(path[1:], keyDelimiter))
+
+		if len(next) == 0 {
+			delete(m, path[0])
+		}
+	}
+}
+
+// savedKey holds the value of a key, or of the first value on its path that is not a section, so that it
+// can be restored after the key has been changed.
+type savedKey struct {
+	key   string
+	value interface{}
+	ok    bool
+}
+
+// saveKey returns the value of the key in m, see savedKey.
+func saveKey(m map[string]interface{}, key string) savedKey {
+	path := splitKey(key)
+
+	for i, section := range path {
+		val, ok := m[section]
+		next, isMap := val.(map[string]interface{})
+
+		if !ok || !isMap || i == len(path)-1 {
+			return savedKey{key: strings.Join(path[:i+1], keyDelimiter), value: copyValue(val), ok: ok}
+		}
+
+		m = next
+	}
+
+	return savedKey{}
+}
+
+// restore sets the saved value in m, or removes the key if it did not have a value.
+func (s savedKey) restore(m map[string]interface{}) {
+	if s.ok {
+		setKey(m, s.key, s.value)
+	} else {
+		pruneKey(m, s.key)
+	}
+}
+
+// copySettings returns a deep copy of m with every key lower-cased.
+func copySettings(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+
+	for key, val := range m {
+		out[strings.ToLower(key)] = copyValue(val)
+	}
+
+	return out
+}
+
+// copyValue returns a deep copy of the maps and slices within val, other values are returned as-is.
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return copySettings(v)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+
+		return out
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[strings.ToLower(cast.ToString(key))] = copyValue(val)
+		}
+
+		return m
+	default:
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Slice && !rv.IsNil() {
+			out := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+			reflect.Copy(out, rv)
+
+			return out.Interface()
+		}
+
+		return val
+	}
+}
+
+// unsetValue marks a key as removed within a layer, hiding any value for the key in lower layers.
+type unsetValue struct{}
+
+// mergeSettings deep merges src into dst, values in src take precedence and maps are merged recursively.
+// Keys marked as unset in src are removed from dst, along with any section left empty by removing them.
+func mergeSettings(dst, src map[string]interface{}) {
+	for key, val := range src {
+		key = strings.ToLower(key)
+
+		if _, ok := val.(unsetValue); ok {
+			delete(dst, key)
+
+			continue
+		}
+
+		srcMap, srcIsMap := val.(map[string]interface{})
+		if !srcIsMap {
+			dst[key] = copyValue(val)
+
+			continue
+		}
+
+		unsetOnly := onlyUnset(srcMap)
+
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if !dstIsMap {
+			if unsetOnly {
+				continue
+			}
+
+			dstMap = map[string]interface{}{}
+			dst[key] = dstMap
+		}
+
+		mergeSettings(dstMap, srcMap)
+
+		if unsetOnly && len(dstMap) == 0 {
+			delete(dst, key)
+		}
+	}
+}
+
+// onlyUnset returns true if the section holds nothing but keys marked as unset.
+func onlyUnset(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+
+	for _, val := range m {
+		switch v := val.(type) {
+		case unsetValue:
+		case map[string]interface{}:
+			if !onlyUnset(v) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeLayers returns the effective settings of the layers merged in order.
+func mergeLayers(layers ...layer) map[string]interface{} {
+	out := map[string]interface{}{}
+
+	for _, l := range layers {
+		l.mergeInto(out)
+	}
+
+	return out
+}
+
+// flattenKeys returns every leaf key in m as a fully qualified key.
+func flattenKeys(m map[string]interface{}, prefix string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key, val := range m {
+		if prefix != "" {
+			key = prefix + keyDelimiter + key
+		}
+
+		if sub, ok := val.(map[string]interface{}); ok && len(sub) > 0 {
+			keys = append(keys, flattenKeys(sub, key)...)
+
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
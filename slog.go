@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewSlogOptions returns slog handler options derived from the same "debug" flag and [log] section as
+// NewZapConfig, with log.level mapped to the matching slog level.
+//
+// Source locations are added in development mode, which can be overridden in the [log] section:
+//
+//	[log]
+//	add_source = true
+//
+// Invalid settings are ignored and returned in a ValidationError along with the options.
+func NewSlogOptions(c Conf) (*slog.HandlerOptions, error) {
+	cfg, err := NewZapConfig(c)
+
+	verr := &ValidationError{}
+	if err != nil && !errors.As(err, &verr) {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:     slogLevel(cfg.Level.Level()),
+		AddSource: cfg.Development,
+	}
+
+	applySetting(c, "log.add_source", verr, ErrInvalidLogConfig, func(v bool) error {
+		opts.AddSource = v
+
+		return nil
+	})
+
+	if len(verr.Violations) > 0 {
+		return opts, verr
+	}
+
+	return opts, nil
+}
+
+// slogOutputs holds the log outputs opened for slog handlers by their paths, the handlers have no way to close
+// them so each set of paths is only opened once and shared.
+//
+//nolint:gochecknoglobals // shared outputs.
+var slogOutputs = struct {
+	sync.Mutex
+	sinks map[string]zapcore.WriteSyncer
+}{sinks: map[string]zapcore.WriteSyncer{}}
+
+// openSlogOutput returns the output writing to the paths, opening it if it is not already open.
+func openSlogOutput(paths []string) (zapcore.WriteSyncer, error) {
+	key := strings.Join(paths, "\x00")
+
+	slogOutputs.Lock()
+	defer slogOutputs.Unlock()
+
+	if out, ok := slogOutputs.sinks[key]; ok {
+		return out, nil
+	}
+
+	out, _, err := zap.Open(paths...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log output: %w", err)
+	}
+
+	slogOutputs.sinks[key] = out
+
+	return out, nil
+}
+
+// newSlogHandler returns a slog handler writing to the log.output_paths of the configuration, using a JSON
+// handler for the json encoding and a text handler for the console encoding.
+func newSlogHandler(c Conf, opts *slog.HandlerOptions) (slog.Handler, error) {
+	cfg, _ := NewZapConfig(c)
+
+	out, err := openSlogOutput(cfg.OutputPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Encoding == "console" {
+		return slog.NewTextHandler(out, opts), nil
+	}
+
+	return slog.NewJSONHandler(out, opts), nil
+}
+
+// slogLevel returns the slog level matching the zap level, zap levels are one apart and slog levels are four
+// apart, so dpanic, panic and fatal map to levels above error.
+func slogLevel(level zapcore.Level) slog.Level {
+	return slog.Level(int(level) * int(slog.LevelWarn-slog.LevelInfo))
+}
+
+// SlogLevel returns a slog.LevelVar that is kept in sync with the configured level, it is updated whenever
+// the configuration is reloaded or changed at runtime, eg. with SetString("log.level", "debug").
+// Every call returns the same level.
+func (v *ViperConf) SlogLevel() *slog.LevelVar {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.slogLevel == nil {
+		v.slogLevel = &slog.LevelVar{}
+		v.slogLevel.Set(slogLevel(zapLevel(v.snapshot())))
+	}
+
+	return v.slogLevel
+}
+
+// SlogOptions returns slog handler options derived from the settings, see NewSlogOptions.
+// The level is SlogLevel, so it follows the configuration without a restart.
+func (v *ViperConf) SlogOptions() *slog.HandlerOptions {
+	opts := v.snapshot().SlogOptions()
+	opts.Level = v.SlogLevel()
+
+	return opts
+}
+
+// SlogHandler returns a slog handler using SlogOptions, writing to the log.output_paths of the configuration.
+// Settings other than the level only take effect when a new handler is created, handlers writing to the same
+// paths share the output rather than opening it again.
+func (v *ViperConf) SlogHandler() (slog.Handler, error) {
+	return newSlogHandler(v.snapshot(), v.SlogOptions())
+}
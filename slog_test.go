@@ -0,0 +1,173 @@
+package config_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/config"
+)
+
+func TestNewSlogOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   map[string]interface{}
+		wantLevel  slog.Level
+		wantSource bool
+	}{
+		{"default", map[string]interface{}{}, slog.LevelInfo, false},
+		{"debug", map[string]interface{}{"debug": true}, slog.LevelDebug, true},
+		{"level", map[string]interface{}{"log": map[string]interface{}{"level": "warn"}}, slog.LevelWarn, false},
+		{"fatal", map[string]interface{}{"log": map[string]interface{}{"level": "fatal"}}, slog.LevelError + 12, false},
+		{"add_source", map[string]interface{}{"log": map[string]interface{}{"add_source": true}}, slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := config.NewSlogOptions(config.NewMemoryConf(tt.settings))
+			if err != nil {
+				t.Fatalf("config.NewSlogOptions(): error, got '%s', want 'nil'", err)
+			}
+
+			if got := opts.Level.Level(); got != tt.wantLevel {
+				t.Errorf("config.NewSlogOptions(): level, got '%s', want '%s'", got, tt.wantLevel)
+			}
+
+			if opts.AddSource != tt.wantSource {
+				t.Errorf("config.NewSlogOptions(): add source, got '%t', want '%t'", opts.AddSource, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestNewSlogOptions_Invalid(t *testing.T) {
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"log": map[string]interface{}{"level": "loud", "add_source": "maybe"},
+	})
+
+	opts, err := config.NewSlogOptions(vcfg)
+
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 {
+		t.Fatalf("config.NewSlogOptions(): error, got '%v', want ValidationError with 2 violations", err)
+	}
+
+	if !errors.Is(err, config.ErrInvalidLogConfig) {
+		t.Errorf("config.NewSlogOptions(): error does not wrap '%s': %s", config.ErrInvalidLogConfig, err)
+	}
+
+	if opts.Level.Level() != slog.LevelInfo || opts.AddSource {
+		t.Errorf("config.NewSlogOptions(): invalid settings were applied, got '%+v'", opts)
+	}
+}
+
+func TestViper_SlogHandler(t *testing.T) {
+	logfile := filepath.Join(t.TempDir(), "test.log")
+
+	vcfg := config.NewViperConfig("test")
+	vcfg.Set("log", map[string]interface{}{"level": "info", "output_paths": []string{logfile}})
+
+	handler, err := vcfg.SlogHandler()
+	if err != nil {
+		t.Fatalf("config.SlogHandler(): error, got '%s', want 'nil'", err)
+	}
+
+	logger := slog.New(handler)
+	logger.Debug("hidden")
+	vcfg.SetString("log.level", "debug")
+	logger.Debug("shown")
+
+	data, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if strings.Contains(string(data), "hidden") || !strings.Contains(string(data), `"msg":"shown"`) {
+		t.Errorf("config.SlogHandler(): log output, got '%s', want only the message logged at debug as JSON", data)
+	}
+}
+
+func TestViper_SlogHandlerText(t *testing.T) {
+	logfile := filepath.Join(t.TempDir(), "test.log")
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"debug": true,
+		"log":   map[string]interface{}{"output_paths": []string{logfile}},
+	})
+
+	handler, err := vcfg.SlogHandler()
+	if err != nil {
+		t.Fatalf("config.SlogHandler(): error, got '%s', want 'nil'", err)
+	}
+
+	slog.New(handler).Debug("shown")
+
+	data, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if !strings.Contains(string(data), "msg=shown") || !strings.Contains(string(data), "source=") {
+		t.Errorf("config.SlogHandler(): log output, got '%s', want text with the source", data)
+	}
+}
+
+func TestViper_SlogHandlerReusesOutput(t *testing.T) {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("os.ReadDir(): open files can not be counted: %s", err)
+	}
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"log": map[string]interface{}{"output_paths": []string{filepath.Join(t.TempDir(), "test.log")}},
+	})
+
+	for range 10 {
+		if _, err = vcfg.SlogHandler(); err != nil {
+			t.Fatalf("config.SlogHandler(): error, got '%s', want 'nil'", err)
+		}
+	}
+
+	after, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Fatalf("os.ReadDir(): error, got '%s', want 'nil'", err)
+	}
+
+	if opened := len(after) - len(fds); opened > 1 {
+		t.Errorf("config.SlogHandler(): open files, got %d more, want at most 1", opened)
+	}
+}
+
+func TestViper_SlogLevelFollowsReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[log]\nlevel = \"warn\"\n")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	vc := vcfg.(*config.ViperConf)
+	level := vc.SlogLevel()
+
+	if got := level.Level(); got != slog.LevelWarn {
+		t.Errorf("config.SlogLevel(): level, got '%s', want '%s'", got, slog.LevelWarn)
+	}
+
+	writeTestFile(t, filename, "debug = true\n")
+
+	if err = vc.Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	if got := level.Level(); got != slog.LevelDebug {
+		t.Errorf("config.SlogLevel(): level, got '%s', want '%s'", got, slog.LevelDebug)
+	}
+
+	if vc.SlogOptions().Level != level {
+		t.Error("config.SlogOptions(): level, got a fixed level, want the SlogLevel")
+	}
+}
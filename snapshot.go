@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// ErrReadOnly is returned when saving a configuration that can not be modified.
+var ErrReadOnly = errors.New("config is read-only")
+
+// Snapshot is a frozen, read-only Conf holding the effective configuration at the moment it was taken.
+//
+// The settings of a Snapshot are never modified, so it can be read from any number of goroutines without
+// locking and every read is consistent with every other. Set and Unset are ignored and Save returns
+// ErrReadOnly.
+type Snapshot struct {
+	settings map[string]interface{}
+	// explicit holds the settings without the defaults, nil if there are no defaults.
+	explicit map[string]interface{}
+}
+
+// emptySnapshot is returned for a ViperConf that has not been built.
+var emptySnapshot = &Snapshot{settings: map[string]interface{}{}} //nolint:gochecknoglobals // immutable.
+
+// newSnapshot returns a Snapshot of the settings, which must not be modified afterwards.
+func newSnapshot(settings map[string]interface{}) *Snapshot {
+	return &Snapshot{settings: settings}
+}
+
+// Snapshot returns the effective configuration as a frozen, read-only Conf.
+func (v *ViperConf) Snapshot() *Snapshot {
+	return v.snapshot()
+}
+
+// snapshot returns the current snapshot without locking.
+func (v *ViperConf) snapshot() *Snapshot {
+	if s := v.current.Load(); s != nil {
+		return s
+	}
+
+	return emptySnapshot
+}
+
+// explicitSettings returns the settings that are not defaults, the result must not be modified.
+func (s *Snapshot) explicitSettings() map[string]interface{} {
+	if s.explicit != nil {
+		return s.explicit
+	}
+
+	return s.settings
+}
+
+// get returns the effective value for the key.
+func (s *Snapshot) get(key string) interface{} {
+	val, _ := lookupKey(s.settings, key)
+
+	return val
+}
+
+// Get can retrieve any value given the key to use.
+// Get is case-insensitive for a key.
+func (s *Snapshot) Get(key string) interface{} {
+	return copyValue(s.get(key))
+}
+
+// GetBool returns the value associated with the key as a boolean.
+func (s *Snapshot) GetBool(key string) bool {
+	return cast.ToBool(s.get(key))
+}
+
+// GetDuration returns the value associated with the key as a duration.
+func (s *Snapshot) GetDuration(key string) time.Duration {
+	return cast.ToDuration(s.get(key))
+}
+
+// GetFloat64 returns the value associated with the key as a float64.
+func (s *Snapshot) GetFloat64(key string) float64 {
+	return cast.ToFloat64(s.get(key))
+}
+
+// GetInt returns the value associated with the key as an int.
+func (s *Snapshot) GetInt(key string) int {
+	return cast.ToInt(s.get(key))
+}
+
+// GetIntSlice returns the value associated with the key as a slice of ints.
+// A comma separated string is split into its items.
+func (s *Snapshot) GetIntSlice(key string) []int {
+	return cast.ToIntSlice(listValue(copyValue(s.get(key))))
+}
+
+// GetString returns the value associated with the key as a string.
+func (s *Snapshot) GetString(key string) string {
+	return cast.ToString(s.get(key))
+}
+
+// GetStringSlice returns the value associated with the key as a slice of strings.
+// A comma separated string is split into its items.
+func (s *Snapshot) GetStringSlice(key string) []string {
+	return cast.ToStringSlice(listValue(copyValue(s.get(key))))
+}
+
+// Set is ignored, a Snapshot is read-only.
+func (s *Snapshot) Set(string, interface{}) {}
+
+// SetBool is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetBool(string, bool) {}
+
+// SetDuration is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetDuration(string, time.Duration) {}
+
+// SetFloat64 is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetFloat64(string, float64) {}
+
+// SetInt is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetInt(string, int) {}
+
+// SetIntSlice is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetIntSlice(string, []int) {}
+
+// SetString is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetString(string, string) {}
+
+// SetStringSlice is ignored, a Snapshot is read-only.
+func (s *Snapshot) SetStringSlice(string, []string) {}
+
+// IsSet returns true if the key has a value.
+// IsSet is case-insensitive for a key.
+func (s *Snapshot) IsSet(key string) bool {
+	_, ok := lookupKey(s.settings, key)
+
+	return ok
+}
+
+// Unset is ignored, a Snapshot is read-only.
+func (s *Snapshot) Unset(string) {}
+
+// AllKeys returns every key that has a value, sorted.
+func (s *Snapshot) AllKeys() []string {
+	keys := flattenKeys(s.settings, "")
+	slices.Sort(keys)
+
+	return keys
+}
+
+// AllSettings returns a copy of all settings as a map[string]interface{}.
+func (s *Snapshot) AllSettings() map[string]interface{} {
+	return copySettings(s.settings)
+}
+
+// ZapConfig returns a zap logger configuration derived from the settings, see NewZapConfig.
+// Invalid settings in the [log] section are ignored.
+func (s *Snapshot) ZapConfig() zap.Config {
+	cfg, _ := NewZapConfig(s)
+
+	return cfg
+}
+
+// SlogOptions returns slog handler options derived from the settings, see NewSlogOptions.
+// Invalid settings in the [log] section are ignored.
+func (s *Snapshot) SlogOptions() *slog.HandlerOptions {
+	opts, _ := NewSlogOptions(s)
+
+	return opts
+}
+
+// SlogHandler returns a slog handler using SlogOptions, writing to the log.output_paths of the settings.
+func (s *Snapshot) SlogHandler() (slog.Handler, error) {
+	return newSlogHandler(s, s.SlogOptions())
+}
+
+// Save returns ErrReadOnly, a Snapshot can not be saved.
+func (s *Snapshot) Save() error {
+	return ErrReadOnly
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/na4ma4/config"
+	"github.com/na4ma4/config/conftest"
+)
+
+func TestSnapshot_Frozen(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project", "testdata/test-project.toml")
+
+	var snap config.Conf = vcfg.(*config.ViperConf).Snapshot()
+
+	vcfg.SetString("server.address", "10.0.0.1:8080")
+	snap.SetString("server.address", "ignored")
+
+	expectGetString(t, snap, "server.address", "127.0.0.1:8080")
+	expectGetString(t, vcfg, "server.address", "10.0.0.1:8080")
+
+	if err := snap.Save(); !errors.Is(err, config.ErrReadOnly) {
+		t.Errorf("config.Save(): error, got '%v', want '%s'", err, config.ErrReadOnly)
+	}
+}
+
+func TestSnapshot_SlicesAreCopied(t *testing.T) {
+	for name, vcfg := range map[string]config.Conf{
+		"viper":  config.NewViperConfig("test"),
+		"memory": config.NewMemoryConf(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			list, ints := []string{"a", "b"}, []int{1, 2}
+			vcfg.SetStringSlice("list", list)
+			vcfg.SetIntSlice("ints", ints)
+			vcfg.Set("items", []interface{}{"a", "b"})
+
+			list[0], ints[0] = "set", 0
+
+			vcfg.GetStringSlice("list")[0] = "get"
+			vcfg.GetIntSlice("ints")[0] = 0
+			vcfg.Get("items").([]interface{})[0] = "get"
+			vcfg.AllSettings()["list"].([]string)[1] = "all"
+
+			conftest.ExpectStringSlice(t, vcfg, "list", []string{"a", "b"})
+			conftest.ExpectIntSlice(t, vcfg, "ints", []int{1, 2})
+			conftest.ExpectStringSlice(t, vcfg, "items", []string{"a", "b"})
+		})
+	}
+}
+
+func TestSnapshot_ConsistentReads(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project", "testdata/test-project.toml")
+	vcfg.SetInt("pair.a", 0)
+	vcfg.SetInt("pair.b", 0)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 1000 {
+			vcfg.Set("pair", map[string]interface{}{"a": i, "b": strconv.Itoa(i)})
+		}
+	}()
+
+	for range 1000 {
+		snap := vcfg.(*config.ViperConf).Snapshot()
+		if a, b := snap.GetInt("pair.a"), snap.GetString("pair.b"); strconv.Itoa(a) != b {
+			t.Fatalf("config.Snapshot(): inconsistent reads, got '%d' and '%s'", a, b)
+		}
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SubConf is a Conf compatible view of the settings below a prefix of a ViperConf.
+//
+// Keys are relative to the prefix, eg. GetString("address") in a view of "server" returns server.address.
+// The view shares the lock, settings and Save target of its parent, so changes made through either are
+// visible in both.
+type SubConf struct {
+	parent *ViperConf
+	prefix string
+}
+
+// Sub returns a view of the settings below the prefix.
+func (v *ViperConf) Sub(prefix string) *SubConf {
+	return &SubConf{parent: v, prefix: strings.ToLower(prefix)}
+}
+
+// Sub returns a view of the settings below the prefix, relative to this view.
+func (s *SubConf) Sub(prefix string) *SubConf {
+	return &SubConf{parent: s.parent, prefix: s.key(prefix)}
+}
+
+// Prefix returns the prefix of the view within its parent.
+func (s *SubConf) Prefix() string {
+	return s.prefix
+}
+
+// key returns the key within the parent for a key relative to the view.
+func (s *SubConf) key(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+
+	if key == "" {
+		return s.prefix
+	}
+
+	return s.prefix + keyDelimiter + key
+}
+
+// Get can retrieve any value given the key to use.
+func (s *SubConf) Get(key string) interface{} {
+	return s.parent.Get(s.key(key))
+}
+
+// GetBool returns the value associated with the key as a boolean.
+func (s *SubConf) GetBool(key string) bool {
+	return s.parent.GetBool(s.key(key))
+}
+
+// GetDuration returns the value associated with the key as a duration.
+func (s *SubConf) GetDuration(key string) time.Duration {
+	return s.parent.GetDuration(s.key(key))
+}
+
+// GetFloat64 returns the value associated with the key as a float64.
+func (s *SubConf) GetFloat64(key string) float64 {
+	return s.parent.GetFloat64(s.key(key))
+}
+
+// GetInt returns the value associated with the key as an int.
+func (s *SubConf) GetInt(key string) int {
+	return s.parent.GetInt(s.key(key))
+}
+
+// GetIntSlice returns the value associated with the key as a slice of ints.
+func (s *SubConf) GetIntSlice(key string) []int {
+	return s.parent.GetIntSlice(s.key(key))
+}
+
+// GetString returns the value associated with the key as a string.
+func (s *SubConf) GetString(key string) string {
+	return s.parent.GetString(s.key(key))
+}
+
+// GetStringSlice returns the value associated with the key as a slice of strings.
+func (s *SubConf) GetStringSlice(key string) []string {
+	return s.parent.GetStringSlice(s.key(key))
+}
+
+// Set sets the value for the key in the override layer.
+func (s *SubConf) Set(key string, value interface{}) {
+	s.parent.Set(s.key(key), value)
+}
+
+// SetBool sets the value for the key in the override layer.
+func (s *SubConf) SetBool(key string, value bool) {
+	s.parent.SetBool(s.key(key), value)
+}
+
+// SetDuration sets the value for the key in the override layer.
+func (s *SubConf) SetDuration(key string, value time.Duration) {
+	s.parent.SetDuration(s.key(key), value)
+}
+
+// SetFloat64 sets the value for the key in the override layer.
+func (s *SubConf) SetFloat64(key string, value float64) {
+	s.parent.SetFloat64(s.key(key), value)
+}
+
+// SetInt sets the value for the key in the override layer.
+func (s *SubConf) SetInt(key string, value int) {
+	s.parent.SetInt(s.key(key), value)
+}
+
+// SetIntSlice sets the value for the key in the override layer.
+func (s *SubConf) SetIntSlice(key string, value []int) {
+	s.parent.SetIntSlice(s.key(key), value)
+}
+
+// SetString sets the value for the key in the override layer.
+func (s *SubConf) SetString(key string, value string) {
+	s.parent.SetString(s.key(key), value)
+}
+
+// SetStringSlice sets the value for the key in the override layer.
+func (s *SubConf) SetStringSlice(key string, value []string) {
+	s.parent.SetStringSlice(s.key(key), value)
+}
+
+// explicitSettings returns the settings within the prefix that are not defaults, the result must not be modified.
+func (s *SubConf) explicitSettings() map[string]interface{} {
+	if m, ok := lookupKey(s.parent.explicitSettings(), s.prefix); ok {
+		if m, ok := m.(map[string]interface{}); ok {
+			return m
+		}
+	}
+
+	return map[string]interface{}{}
+}
+
+// saveOverride returns a function that restores the override layer for the key to its current state.
+func (s *SubConf) saveOverride(key string) func() {
+	return s.parent.saveOverride(s.key(key))
+}
+
+// SetDefault sets the default value for this key.
+func (s *SubConf) SetDefault(key string, value interface{}) {
+	s.parent.SetDefault(s.key(key), value)
+}
+
+// IsSet returns true if the key has a value in any layer, including defaults.
+func (s *SubConf) IsSet(key string) bool {
+	return s.parent.IsSet(s.key(key))
+}
+
+// Unset removes the key (or section) from the configuration.
+func (s *SubConf) Unset(key string) {
+	s.parent.Unset(s.key(key))
+}
+
+// AllKeys returns every key below the prefix that has a value, relative to the prefix and sorted.
+func (s *SubConf) AllKeys() []string {
+	keys := flattenKeys(s.AllSettings(), "")
+	slices.Sort(keys)
+
+	return keys
+}
+
+// AllSettings returns the settings below the prefix as a map[string]interface{}.
+func (s *SubConf) AllSettings() map[string]interface{} {
+	if s.prefix == "" {
+		return s.parent.AllSettings()
+	}
+
+	if m, ok := s.parent.Get(s.prefix).(map[string]interface{}); ok {
+		return m
+	}
+
+	return map[string]interface{}{}
+}
+
+// ZapConfig returns the zap logger configuration of the parent.
+func (s *SubConf) ZapConfig() zap.Config {
+	return s.parent.ZapConfig()
+}
+
+// SlogOptions returns the slog handler options of the parent.
+func (s *SubConf) SlogOptions() *slog.HandlerOptions {
+	return s.parent.SlogOptions()
+}
+
+// SlogHandler returns a slog handler of the parent.
+func (s *SubConf) SlogHandler() (slog.Handler, error) {
+	return s.parent.SlogHandler()
+}
+
+// Save writes the whole config of the parent to its file.
+func (s *SubConf) Save() error {
+	return s.parent.Save()
+}
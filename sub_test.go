@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestSub_RelativeKeys(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project", "testdata/test-project.toml")
+
+	var server config.Conf = vcfg.(*config.ViperConf).Sub("server")
+
+	expectGetString(t, server, "address", "127.0.0.1:8080")
+
+	server.SetInt("port", 8080)
+	expectGetInt(t, vcfg, "server.port", 8080)
+
+	vcfg.SetString("server.address", "10.0.0.1:8080")
+	expectGetString(t, server, "address", "10.0.0.1:8080")
+
+	if diff := cmp.Diff(server.AllKeys(), []string{"address", "port"}); diff != "" {
+		t.Errorf("config.AllKeys(): -got +want:\n%s", diff)
+	}
+
+	server.Unset("port")
+
+	if vcfg.IsSet("server.port") {
+		t.Error("config.IsSet(): server.port got 'true', want 'false'")
+	}
+}
+
+func TestSub_Nested(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project", "testdata/test-project.toml")
+	vcfg.SetString("database.primary.host", "db1")
+
+	primary := vcfg.(*config.ViperConf).Sub("database").Sub("primary")
+
+	expectGetString(t, primary, "host", "db1")
+
+	if primary.Prefix() != "database.primary" {
+		t.Errorf("config.Prefix(): got '%s', want 'database.primary'", primary.Prefix())
+	}
+
+	if diff := cmp.Diff(vcfg.(*config.ViperConf).Sub("missing").AllSettings(), map[string]interface{}{}); diff != "" {
+		t.Errorf("config.AllSettings(): -got +want:\n%s", diff)
+	}
+}
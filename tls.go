@@ -0,0 +1,369 @@
+package config
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTLSConfig is returned when a TLS section holds an invalid setting.
+var ErrInvalidTLSConfig = errors.New("invalid tls setting")
+
+// errNoServerCertificate is returned when a client can not verify the server as it did not send a certificate.
+var errNoServerCertificate = errors.New("server did not provide a certificate")
+
+// TLSMode selects whether TLSConfig builds the configuration of a server or of a client.
+type TLSMode int
+
+const (
+	// TLSServer builds a configuration for a server, a certificate and key are required.
+	TLSServer TLSMode = iota
+	// TLSClient builds a configuration for a client, a certificate and key are optional.
+	TLSClient
+)
+
+//nolint:gochecknoglobals // constant lists.
+var (
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+	tlsClientAuth = map[string]tls.ClientAuthType{
+		"none":               tls.NoClientCert,
+		"request":            tls.RequestClientCert,
+		"require":            tls.RequireAnyClientCert,
+		"verify_if_given":    tls.VerifyClientCertIfGiven,
+		"require_and_verify": tls.RequireAndVerifyClientCert,
+	}
+	tlsFileKeys = []string{"cert_file", "key_file", "ca_file"}
+)
+
+// TLSConfig returns a TLS configuration built from the keys in the prefix section, eg. server.tls:
+//
+//	[server.tls]
+//	cert_file = "/etc/project/tls/server.crt"
+//	key_file = "/etc/project/tls/server.key"
+//	ca_file = ["/etc/project/tls/ca.crt"]  # client CAs for a server, root CAs for a client
+//	min_version = "1.2"                    # 1.0, 1.1, 1.2 or 1.3, defaults to 1.2
+//	max_version = "1.3"
+//	cipher_suites = ["TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"]
+//	client_auth = "require_and_verify"     # none, request, require, verify_if_given or require_and_verify
+//	server_name = "api.example.com"        # client only
+//	insecure_skip_verify = false           # client only
+//
+// The certificate, key and CA files are loaded and checked before TLSConfig returns, any invalid settings are
+// returned in a ValidationError. A server requires client certificates signed by the CAs when a ca_file is set
+// and client_auth is not.
+//
+// When the files or the settings in the section change, the configuration is rebuilt on the next handshake, so
+// renewed certificates are picked up without a restart. Changes are checked for at most once per second, and
+// if the new settings are invalid the previous configuration is kept.
+//
+// Fields the caller sets on the returned configuration, eg. NextProtos for ALPN, apply to every handshake, a
+// server only replaces the fields set by the section when it is rebuilt. A client reloads its certificate and
+// verifies servers against the current ca_file, server_name and insecure_skip_verify in VerifyConnection
+// (InsecureSkipVerify is set so the fixed RootCAs are not used), so VerifyConnection must not be replaced.
+// The versions and cipher suites of a client are fixed when TLSConfig returns.
+func TLSConfig(c Conf, prefix string, mode TLSMode) (*tls.Config, error) {
+	src := &tlsSource{c: c, prefix: strings.ToLower(prefix), mode: mode}
+	src.stamp, src.checked = src.fingerprint(), time.Now()
+
+	cfg, err := src.load()
+	if err != nil {
+		return nil, err
+	}
+
+	src.cfg = cfg
+	out := cfg.Clone()
+
+	switch mode {
+	case TLSServer:
+		out.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := out.Clone()
+			cfg.GetConfigForClient = nil
+			applyTLSSection(cfg, src.current())
+
+			return cfg, nil
+		}
+	case TLSClient:
+		out.Certificates = nil
+		out.InsecureSkipVerify = true //nolint:gosec // verified by VerifyConnection.
+		out.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyTLSServer(src.current(), cs)
+		}
+		out.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if certs := src.current().Certificates; len(certs) > 0 {
+				return &certs[0], nil
+			}
+
+			return &tls.Certificate{}, nil
+		}
+	}
+
+	return out, nil
+}
+
+// tlsCheckInterval is how often the section and its files are checked for changes.
+const tlsCheckInterval = time.Second
+
+// tlsSource builds a TLS configuration from a section, rebuilding it when the section or its files change.
+type tlsSource struct {
+	lock    sync.Mutex
+	c       Conf
+	prefix  string
+	mode    TLSMode
+	cfg     *tls.Config
+	stamp   string
+	checked time.Time
+}
+
+// current returns the configuration, rebuilding it first if the section or its files have changed since they
+// were last checked, they are checked at most once every tlsCheckInterval.
+func (s *tlsSource) current() *tls.Config {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if time.Since(s.checked) < tlsCheckInterval {
+		return s.cfg
+	}
+
+	s.checked = time.Now()
+
+	if stamp := s.fingerprint(); stamp != s.stamp {
+		// The stamp is updated even if the new settings are invalid, so they are not loaded on every handshake.
+		s.stamp = stamp
+
+		if cfg, err := s.load(); err == nil {
+			s.cfg = cfg
+		}
+	}
+
+	return s.cfg
+}
+
+// fingerprint describes the settings of the section and the size and modification time of its files.
+func (s *tlsSource) fingerprint() string {
+	var b strings.Builder
+
+	fmt.Fprint(&b, s.c.Get(s.prefix))
+
+	for _, key := range tlsFileKeys {
+		for _, name := range s.c.GetStringSlice(joinKey(s.prefix, key)) {
+			if st, err := os.Stat(name); err == nil {
+				fmt.Fprintf(&b, "|%s:%d:%d", name, st.Size(), st.ModTime().UnixNano())
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// load builds the configuration from the settings of the section, loading the certificate, key and CA files.
+//
+//nolint:cyclop,funlen // one block per setting.
+func (s *tlsSource) load() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	verr := &ValidationError{}
+
+	key := func(name string) string {
+		return joinKey(s.prefix, name)
+	}
+
+	certFile, keyFile := s.c.GetString(key("cert_file")), s.c.GetString(key("key_file"))
+
+	switch {
+	case certFile == "" && keyFile == "" && s.mode == TLSServer:
+		verr.Violations = append(verr.Violations, Violation{Key: key("cert_file"), Err: ErrRequired})
+	case certFile == "" && keyFile != "":
+		verr.Violations = append(verr.Violations, Violation{Key: key("cert_file"), Err: ErrRequired})
+	case keyFile == "" && certFile != "":
+		verr.Violations = append(verr.Violations, Violation{Key: key("key_file"), Err: ErrRequired})
+	case certFile != "":
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			verr.Violations = append(verr.Violations, Violation{
+				Key: key("cert_file"),
+				Err: fmt.Errorf("%w: unable to load certificate: %w", ErrInvalidTLSConfig, err),
+			})
+
+			break
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	applySetting(s.c, key("ca_file"), verr, ErrInvalidTLSConfig, func(v []string) error {
+		pool, err := loadCertPool(v)
+		if err != nil {
+			return err
+		}
+
+		if s.mode == TLSServer {
+			cfg.ClientCAs = pool
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		} else {
+			cfg.RootCAs = pool
+		}
+
+		return nil
+	})
+	applySetting(s.c, key("min_version"), verr, ErrInvalidTLSConfig, func(v string) error {
+		return tlsVersion(&cfg.MinVersion, v)
+	})
+	applySetting(s.c, key("max_version"), verr, ErrInvalidTLSConfig, func(v string) error {
+		if err := tlsVersion(&cfg.MaxVersion, v); err != nil {
+			return err
+		}
+
+		if cfg.MaxVersion < cfg.MinVersion {
+			return fmt.Errorf("%w: %s is lower than min_version", ErrOutOfRange, v)
+		}
+
+		return nil
+	})
+	applySetting(s.c, key("cipher_suites"), verr, ErrInvalidTLSConfig, func(v []string) error {
+		return tlsCipherSuites(&cfg.CipherSuites, v)
+	})
+
+	if s.mode == TLSServer {
+		applySetting(s.c, key("client_auth"), verr, ErrInvalidTLSConfig, func(v string) error {
+			auth, ok := tlsClientAuth[strings.ReplaceAll(strings.ToLower(v), "-", "_")]
+			if !ok {
+				return fmt.Errorf("%w: \"%s\" is not one of none, request, require, verify_if_given, require_and_verify",
+					ErrNotAllowed, v)
+			}
+
+			cfg.ClientAuth = auth
+
+			return nil
+		})
+	}
+
+	if s.mode == TLSClient {
+		applySetting(s.c, key("server_name"), verr, ErrInvalidTLSConfig, func(v string) error {
+			cfg.ServerName = v
+
+			return nil
+		})
+		applySetting(s.c, key("insecure_skip_verify"), verr, ErrInvalidTLSConfig, func(v bool) error {
+			cfg.InsecureSkipVerify = v //nolint:gosec // explicitly configured.
+
+			return nil
+		})
+	}
+
+	if len(verr.Violations) > 0 {
+		return nil, verr
+	}
+
+	return cfg, nil
+}
+
+// verifyTLSServer verifies the certificate of the server against the CAs and server name in cfg, the same way
+// crypto/tls does when InsecureSkipVerify is not set.
+func verifyTLSServer(cfg *tls.Config, cs tls.ConnectionState) error {
+	if cfg.InsecureSkipVerify {
+		return nil
+	}
+
+	if len(cs.PeerCertificates) == 0 {
+		return errNoServerCertificate
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         cfg.RootCAs,
+		DNSName:       cmp.Or(cfg.ServerName, cs.ServerName),
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+		return fmt.Errorf("unable to verify server certificate: %w", err)
+	}
+
+	return nil
+}
+
+// applyTLSSection copies the server fields set by the section from src to dst, leaving the fields set by the
+// caller.
+func applyTLSSection(dst, src *tls.Config) {
+	dst.Certificates = src.Certificates
+	dst.ClientCAs = src.ClientCAs
+	dst.ClientAuth = src.ClientAuth
+	dst.MinVersion = src.MinVersion
+	dst.MaxVersion = src.MaxVersion
+	dst.CipherSuites = src.CipherSuites
+}
+
+// loadCertPool returns a pool of the certificates in the PEM files.
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA file: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: no certificates found in %s", ErrInvalidFormat, name)
+		}
+	}
+
+	return pool, nil
+}
+
+// tlsVersion sets dst to the TLS version, eg. "1.2" or "TLS1.2".
+func tlsVersion(dst *uint16, val string) error {
+	name := strings.NewReplacer("tls", "", "v", "", " ", "", "_", ".").Replace(strings.ToLower(val))
+	if name == "1" {
+		name = "1.0"
+	}
+
+	version, ok := tlsVersions[name]
+	if !ok {
+		return fmt.Errorf("%w: \"%s\" is not one of 1.0, 1.1, 1.2, 1.3", ErrNotAllowed, val)
+	}
+
+	*dst = version
+
+	return nil
+}
+
+// tlsCipherSuites sets dst to the IDs of the named cipher suites, insecure cipher suites are not allowed.
+func tlsCipherSuites(dst *[]uint16, names []string) error {
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		idx := slices.IndexFunc(tls.CipherSuites(), func(cs *tls.CipherSuite) bool {
+			return strings.EqualFold(cs.Name, name)
+		})
+
+		switch {
+		case idx >= 0:
+			ids = append(ids, tls.CipherSuites()[idx].ID)
+		case slices.ContainsFunc(tls.InsecureCipherSuites(), func(cs *tls.CipherSuite) bool {
+			return strings.EqualFold(cs.Name, name)
+		}):
+			return fmt.Errorf("%w: cipher suite %s is insecure", ErrNotAllowed, name)
+		default:
+			return fmt.Errorf("%w: unknown cipher suite %s", ErrNotAllowed, name)
+		}
+	}
+
+	*dst = ids
+
+	return nil
+}
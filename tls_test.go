@@ -0,0 +1,401 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+// writeTestCert writes a certificate for name signed by the parent (or self-signed if parent is nil) and its
+// key to dir, returning the certificate and the paths of the files.
+func writeTestCert(
+	t *testing.T, dir, name string, parent *tls.Certificate,
+) (*tls.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(): error, got '%s', want 'nil'", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := tmpl, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate(): error, got '%s', want 'nil'", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("x509.MarshalECPrivateKey(): error, got '%s', want 'nil'", err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	writeTestFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	writeTestFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+
+	leaf, _ := x509.ParseCertificate(der)
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, certFile, keyFile
+}
+
+// handshake runs a handshake between a server and a client over a loopback connection, which (unlike a pipe)
+// buffers the alert sent by a client that gives up, returning the state of the client.
+func handshake(serverCfg, clientCfg *tls.Config) (tls.ConnectionState, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer l.Close()
+
+	errs := make(chan error, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			errs <- err
+
+			return
+		}
+		defer conn.Close()
+
+		errs <- tls.Server(conn, serverCfg).Handshake()
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+
+	client := tls.Client(conn, clientCfg)
+	err = client.Handshake()
+
+	// Closing the client unblocks the server if the client gave up.
+	_ = conn.Close()
+
+	if serverErr := <-errs; err == nil {
+		err = serverErr
+	}
+
+	return client.ConnectionState(), err
+}
+
+func TestTLSConfig_Handshake(t *testing.T) {
+	dir := t.TempDir()
+	ca, caFile, _ := writeTestCert(t, dir, "ca", nil)
+	_, serverCert, serverKey := writeTestCert(t, dir, "server.test", ca)
+	_, clientCert, clientKey := writeTestCert(t, dir, "client.test", ca)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"server": map[string]interface{}{"tls": map[string]interface{}{
+			"cert_file":   serverCert,
+			"key_file":    serverKey,
+			"ca_file":     caFile,
+			"min_version": "1.3",
+		}},
+		"client": map[string]interface{}{"tls": map[string]interface{}{
+			"cert_file":   clientCert,
+			"key_file":    clientKey,
+			"ca_file":     []string{caFile},
+			"server_name": "server.test",
+		}},
+	})
+
+	serverCfg, err := config.TLSConfig(vcfg, "server.tls", config.TLSServer)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): server error, got '%s', want 'nil'", err)
+	}
+
+	if serverCfg.ClientAuth != tls.RequireAndVerifyClientCert || serverCfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("config.TLSConfig(): server, got client auth '%s' and min version '%x', want verified clients and TLS 1.3",
+			serverCfg.ClientAuth, serverCfg.MinVersion)
+	}
+
+	clientCfg, err := config.TLSConfig(vcfg, "client.tls", config.TLSClient)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): client error, got '%s', want 'nil'", err)
+	}
+
+	state, err := handshake(serverCfg, clientCfg)
+	if err != nil {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%s', want 'nil'", err)
+	}
+
+	if got := state.Version; got != tls.VersionTLS13 {
+		t.Errorf("tls.Conn.ConnectionState(): version, got '%x', want '%x'", got, tls.VersionTLS13)
+	}
+}
+
+func TestTLSConfig_ALPN(t *testing.T) {
+	dir := t.TempDir()
+	ca, caFile, _ := writeTestCert(t, dir, "ca", nil)
+	_, certFile, keyFile := writeTestCert(t, dir, "server.test", ca)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"server": map[string]interface{}{"tls": map[string]interface{}{"cert_file": certFile, "key_file": keyFile}},
+		"client": map[string]interface{}{"tls": map[string]interface{}{"ca_file": caFile, "server_name": "server.test"}},
+	})
+
+	serverCfg, err := config.TLSConfig(vcfg, "server.tls", config.TLSServer)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): server error, got '%s', want 'nil'", err)
+	}
+
+	clientCfg, err := config.TLSConfig(vcfg, "client.tls", config.TLSClient)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): client error, got '%s', want 'nil'", err)
+	}
+
+	serverCfg.NextProtos = []string{"h2", "http/1.1"}
+	clientCfg.NextProtos = []string{"h2"}
+
+	state, err := handshake(serverCfg, clientCfg)
+	if err != nil {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%s', want 'nil'", err)
+	}
+
+	if got := state.NegotiatedProtocol; got != "h2" {
+		t.Errorf("tls.Conn.ConnectionState(): negotiated protocol, got '%s', want 'h2'", got)
+	}
+}
+
+func TestTLSConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, _ := writeTestCert(t, dir, "server.test", nil)
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		mode     config.TLSMode
+		wantKeys []string
+		wantErr  error
+	}{
+		{"missing certificate", map[string]interface{}{}, config.TLSServer, []string{"tls.cert_file"}, config.ErrRequired},
+		{"missing key", map[string]interface{}{"cert_file": certFile}, config.TLSClient, []string{"tls.key_file"}, config.ErrRequired},
+		{"missing files", map[string]interface{}{
+			"cert_file": filepath.Join(dir, "missing.crt"),
+			"key_file":  filepath.Join(dir, "missing.key"),
+			"ca_file":   filepath.Join(dir, "missing.crt"),
+		}, config.TLSServer, []string{"tls.cert_file", "tls.ca_file"}, os.ErrNotExist},
+		{"bad versions", map[string]interface{}{"min_version": "1.3", "max_version": "1.2"}, config.TLSClient,
+			[]string{"tls.max_version"}, config.ErrOutOfRange},
+		{"unknown version", map[string]interface{}{"min_version": "2.0"}, config.TLSClient,
+			[]string{"tls.min_version"}, config.ErrNotAllowed},
+		{"insecure cipher", map[string]interface{}{"cipher_suites": []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			config.TLSClient, []string{"tls.cipher_suites"}, config.ErrNotAllowed},
+		{"client auth", map[string]interface{}{
+			"cert_file": certFile, "key_file": filepath.Join(dir, "server.test.key"), "client_auth": "sometimes",
+		}, config.TLSServer, []string{"tls.client_auth"}, config.ErrNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcfg := config.NewMemoryConf(map[string]interface{}{"tls": tt.settings})
+
+			_, err := config.TLSConfig(vcfg, "tls", tt.mode)
+
+			var verr *config.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("config.TLSConfig(): error, got '%v', want ValidationError", err)
+			}
+
+			keys := []string{}
+			for _, v := range verr.Violations {
+				keys = append(keys, v.Key)
+			}
+
+			if diff := cmp.Diff(keys, tt.wantKeys); diff != "" {
+				t.Errorf("config.TLSConfig(): violations -got +want:\n%s", diff)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("config.TLSConfig(): error, got '%s', want '%s'", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_Client(t *testing.T) {
+	dir := t.TempDir()
+	_, caFile, _ := writeTestCert(t, dir, "ca", nil)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{"tls": map[string]interface{}{
+		"ca_file":       caFile,
+		"min_version":   "TLS1.2",
+		"max_version":   "tls1.3",
+		"cipher_suites": []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+		"server_name":   "api.test",
+	}})
+
+	cfg, err := config.TLSConfig(vcfg, "tls", config.TLSClient)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): error, got '%s', want 'nil'", err)
+	}
+
+	if cfg.RootCAs == nil || cfg.ServerName != "api.test" {
+		t.Errorf("config.TLSConfig(): got root CAs '%v' and server name '%s', want CAs and 'api.test'",
+			cfg.RootCAs, cfg.ServerName)
+	}
+
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.MaxVersion != tls.VersionTLS13 {
+		t.Errorf("config.TLSConfig(): versions, got '%x' to '%x', want TLS 1.2 to 1.3", cfg.MinVersion, cfg.MaxVersion)
+	}
+
+	if diff := cmp.Diff(cfg.CipherSuites, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}); diff != "" {
+		t.Errorf("config.TLSConfig(): cipher suites -got +want:\n%s", diff)
+	}
+
+	cert, err := cfg.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil || len(cert.Certificate) != 0 {
+		t.Errorf("config.TLSConfig(): client certificate, got '%v' (%v), want empty", cert, err)
+	}
+}
+
+func TestTLSConfig_Reload(t *testing.T) {
+	dir := t.TempDir()
+	first, certFile, keyFile := writeTestCert(t, dir, "server.test", nil)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{"tls": map[string]interface{}{
+		"cert_file": certFile,
+		"key_file":  keyFile,
+	}})
+
+	cfg, err := config.TLSConfig(vcfg, "tls", config.TLSServer)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): error, got '%s', want 'nil'", err)
+	}
+
+	// expectLeaf waits for the certificate to be want, changes are only checked for once a second.
+	expectLeaf := func(want *tls.Certificate) {
+		t.Helper()
+
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(100 * time.Millisecond) {
+			current, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatalf("tls.Config.GetConfigForClient(): error, got '%s', want 'nil'", err)
+			}
+
+			got := current.Certificates[0].Leaf
+			if got.Equal(want.Leaf) {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("tls.Config.GetConfigForClient(): certificate serial, got '%s', want '%s'",
+					got.SerialNumber, want.Leaf.SerialNumber)
+			}
+		}
+	}
+
+	expectLeaf(first)
+
+	// Renewed files with the same name.
+	second, _, _ := writeTestCert(t, dir, "server.test", nil)
+	future := time.Now().Add(time.Minute)
+
+	for _, name := range []string{certFile, keyFile} {
+		if err = os.Chtimes(name, future, future); err != nil {
+			t.Fatalf("os.Chtimes(): error, got '%s', want 'nil'", err)
+		}
+	}
+
+	expectLeaf(second)
+
+	// New files in the config.
+	third, otherCert, otherKey := writeTestCert(t, dir, "other.test", nil)
+	vcfg.SetString("tls.cert_file", otherCert)
+	vcfg.SetString("tls.key_file", otherKey)
+	expectLeaf(third)
+
+	// Invalid settings keep the previous certificate.
+	vcfg.SetString("tls.cert_file", filepath.Join(dir, "missing.crt"))
+	time.Sleep(time.Second)
+	expectLeaf(third)
+}
+
+func TestTLSConfig_ClientReload(t *testing.T) {
+	dir := t.TempDir()
+	oldCA, oldCAFile, _ := writeTestCert(t, dir, "old-ca", nil)
+	newCA, newCAFile, _ := writeTestCert(t, dir, "new-ca", nil)
+	_, oldCert, oldKey := writeTestCert(t, t.TempDir(), "server.test", oldCA)
+	_, newCert, newKey := writeTestCert(t, t.TempDir(), "server.test", newCA)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"server": map[string]interface{}{"tls": map[string]interface{}{"cert_file": oldCert, "key_file": oldKey}},
+		"client": map[string]interface{}{"tls": map[string]interface{}{
+			"ca_file":     oldCAFile,
+			"server_name": "server.test",
+		}},
+	})
+
+	serverCfg, err := config.TLSConfig(vcfg, "server.tls", config.TLSServer)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): server error, got '%s', want 'nil'", err)
+	}
+
+	clientCfg, err := config.TLSConfig(vcfg, "client.tls", config.TLSClient)
+	if err != nil {
+		t.Fatalf("config.TLSConfig(): client error, got '%s', want 'nil'", err)
+	}
+
+	if _, err = handshake(serverCfg, clientCfg); err != nil {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%s', want 'nil'", err)
+	}
+
+	// The server moves to a certificate from the new CA before the client trusts it.
+	vcfg.SetString("server.tls.cert_file", newCert)
+	vcfg.SetString("server.tls.key_file", newKey)
+	time.Sleep(time.Second)
+
+	var authErr x509.UnknownAuthorityError
+	if _, err = handshake(serverCfg, clientCfg); !errors.As(err, &authErr) {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%v', want unknown authority", err)
+	}
+
+	// The rotated CA bundle is picked up by the client.
+	vcfg.SetStringSlice("client.tls.ca_file", []string{oldCAFile, newCAFile})
+	time.Sleep(time.Second)
+
+	if _, err = handshake(serverCfg, clientCfg); err != nil {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%s', want 'nil'", err)
+	}
+
+	// A different server name is verified.
+	vcfg.SetString("client.tls.server_name", "other.test")
+	time.Sleep(time.Second)
+
+	var nameErr x509.HostnameError
+	if _, err = handshake(serverCfg, clientCfg); !errors.As(err, &nameErr) {
+		t.Fatalf("tls.Conn.Handshake(): error, got '%v', want name mismatch", err)
+	}
+}
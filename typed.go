@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrKeyNotFound is returned when a key has no value in the configuration.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ConversionError is returned when the value of a key can not be converted to the requested type.
+type ConversionError struct {
+	// Key is the key that was requested.
+	Key string
+	// Value is the value found for the key.
+	Value interface{}
+	// Type is the type the value could not be converted to.
+	Type reflect.Type
+	// Err is the underlying conversion error.
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("unable to convert key \"%s\" value %#v (%T) to %s: %s", e.Key, e.Value, e.Value, e.Type, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Get returns the value of the key converted to T, using the same conversions as the Get____ methods.
+//
+// Unlike the Get____ methods, an error wrapping ErrKeyNotFound is returned when the key has no value and a
+// ConversionError is returned when the value can not be converted, rather than returning the zero value.
+// Types without a direct conversion, such as structs, are decoded in the same way as Unmarshal.
+func Get[T any](c Conf, key string) (T, error) {
+	var zero T
+
+	val := c.Get(key)
+	if val == nil {
+		return zero, fmt.Errorf("%w: \"%s\"", ErrKeyNotFound, key)
+	}
+
+	out, err := convertValue[T](val)
+	if err != nil {
+		return zero, &ConversionError{
+			Key:   key,
+			Value: val,
+			Type:  reflect.TypeOf(&zero).Elem(),
+			Err:   err,
+		}
+	}
+
+	return out, nil
+}
+
+// GetOr returns the value of the key converted to T, or fallback if the key has no value or can not be converted.
+func GetOr[T any](c Conf, key string, fallback T) T {
+	out, err := Get[T](c, key)
+	if err != nil {
+		return fallback
+	}
+
+	return out
+}
+
+// convertValue converts val to T using the cast conversions, falling back to decoding for other types.
+//
+//nolint:cyclop // one case per supported type.
+func convertValue[T any](val interface{}) (T, error) {
+	var out T
+
+	if v, ok := val.(T); ok {
+		return v, nil
+	}
+
+	var (
+		res interface{}
+		err error
+	)
+
+	switch any(out).(type) {
+	case string:
+		res, err = cast.ToStringE(val)
+	case bool:
+		res, err = cast.ToBoolE(val)
+	case int:
+		res, err = cast.ToIntE(val)
+	case int32:
+		res, err = cast.ToInt32E(val)
+	case int64:
+		res, err = cast.ToInt64E(val)
+	case uint:
+		res, err = cast.ToUintE(val)
+	case uint32:
+		res, err = cast.ToUint32E(val)
+	case uint64:
+		res, err = cast.ToUint64E(val)
+	case float32:
+		res, err = cast.ToFloat32E(val)
+	case float64:
+		res, err = cast.ToFloat64E(val)
+	case time.Duration:
+		res, err = cast.ToDurationE(val)
+	case time.Time:
+		res, err = cast.ToTimeE(val)
+	case []string:
+		res, err = cast.ToStringSliceE(listValue(val))
+	case []int:
+		res, err = cast.ToIntSliceE(listValue(val))
+	case []bool:
+		res, err = cast.ToBoolSliceE(val)
+	case []time.Duration:
+		res, err = cast.ToDurationSliceE(val)
+	case map[string]interface{}:
+		res, err = cast.ToStringMapE(val)
+	case map[string]string:
+		res, err = cast.ToStringMapStringE(val)
+	default:
+		err = decodeSettings(val, &out)
+
+		return out, err
+	}
+
+	if err != nil {
+		return out, err
+	}
+
+	out, _ = res.(T)
+
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func TestGet(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+
+	if v, err := config.Get[int](vcfg, "typing.int"); err != nil || v != 1337 {
+		t.Errorf("config.Get[int](): got '%d' '%v', want '%d' 'nil'", v, err, 1337)
+	}
+
+	if v, err := config.Get[time.Duration](vcfg, "typing.duration"); err != nil || v != 10*time.Second {
+		t.Errorf("config.Get[time.Duration](): got '%s' '%v', want '%s' 'nil'", v, err, 10*time.Second)
+	}
+
+	v, err := config.Get[[]string](vcfg, "typing.stringslice")
+	if err != nil {
+		t.Errorf("config.Get[[]string](): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(v, []string{"one", "two", "three"}); diff != "" {
+		t.Errorf("config.Get[[]string](): -got +want:\n%s", diff)
+	}
+
+	var typing testTyping
+	if typing, err = config.Get[testTyping](vcfg, "typing"); err != nil || typing.Int != 1337 {
+		t.Errorf("config.Get[struct](): got '%d' '%v', want '%d' 'nil'", typing.Int, err, 1337)
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+	vcfg.SetString("typing.int", "abc")
+
+	if _, err := config.Get[string](vcfg, "missing.key"); !errors.Is(err, config.ErrKeyNotFound) {
+		t.Errorf("config.Get[string](): error, got '%v', want config.ErrKeyNotFound", err)
+	}
+
+	_, err := config.Get[int](vcfg, "typing.int")
+
+	var cerr *config.ConversionError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("config.Get[int](): error, got '%v', want config.ConversionError", err)
+	}
+
+	if cerr.Key != "typing.int" || cerr.Value != "abc" || cerr.Type.String() != "int" {
+		t.Errorf("config.Get[int](): error got '%s' '%v' '%s', want 'typing.int' 'abc' 'int'",
+			cerr.Key, cerr.Value, cerr.Type,
+		)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+	vcfg.SetString("typing.int", "abc")
+
+	if v := config.GetOr(vcfg, "typing.int", 42); v != 42 {
+		t.Errorf("config.GetOr(): invalid got '%d', want '%d'", v, 42)
+	}
+
+	if v := config.GetOr(vcfg, "missing.key", "fallback"); v != "fallback" {
+		t.Errorf("config.GetOr(): missing got '%s', want '%s'", v, "fallback")
+	}
+
+	if v := config.GetOr(vcfg, "typing.float64", 1.0); v != 3.1415 {
+		t.Errorf("config.GetOr(): found got '%f', want '%f'", v, 3.1415)
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// unmarshalTagName is the struct field tag used to name the key a field is decoded from,
+// fields without a tag are matched case-insensitively by name.
+const unmarshalTagName = "config"
+
+// FieldError describes a single struct field that could not be decoded.
+type FieldError struct {
+	// Field is the path of the field, eg. server.port.
+	Field string
+	// Err is the reason the field could not be decoded.
+	Err error
+}
+
+// UnmarshalError is returned when one or more fields could not be decoded from the configuration.
+type UnmarshalError struct {
+	Fields []FieldError
+}
+
+func (e *UnmarshalError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", field.Field, field.Err))
+	}
+
+	return "unable to unmarshal config: " + strings.Join(msgs, "; ")
+}
+
+// Unmarshal decodes every setting in the Conf into out, which must be a pointer to a struct or map.
+func Unmarshal(c Conf, out interface{}) error {
+	return UnmarshalKey(c, "", out)
+}
+
+// UnmarshalKey decodes the settings below the prefix in the Conf into out,
+// which must be a pointer to a struct or map.
+func UnmarshalKey(c Conf, prefix string, out interface{}) error {
+	if prefix != "" {
+		return decodeSettings(c.Get(prefix), out)
+	}
+
+	return decodeSettings(c.AllSettings(), out)
+}
+
+// Unmarshal decodes every setting into out, which must be a pointer to a struct or map.
+//
+// Fields are matched to keys by their `config` tag or case-insensitively by name, strings are converted to
+// time.Duration and encoding.TextUnmarshaler types, and comma separated strings to slices.
+// Any fields that could not be decoded are returned in an UnmarshalError.
+func (v *ViperConf) Unmarshal(out interface{}) error {
+	return decodeSettings(v.AllSettings(), out)
+}
+
+// UnmarshalKey decodes the settings below the prefix into out, which must be a pointer to a struct or map.
+func (v *ViperConf) UnmarshalKey(prefix string, out interface{}) error {
+	return decodeSettings(v.Get(prefix), out)
+}
+
+// decodeSettings decodes input into out, collecting the fields that failed into an UnmarshalError.
+func decodeSettings(input, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.TextUnmarshallerHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           out,
+		TagName:          unmarshalTagName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create decoder: %w", err)
+	}
+
+	if err = decoder.Decode(input); err != nil {
+		uerr := &UnmarshalError{}
+		collectFieldErrors(err, uerr)
+
+		return uerr
+	}
+
+	return nil
+}
+
+// collectFieldErrors flattens the errors returned by the decoder into one FieldError per failed field.
+func collectFieldErrors(err error, uerr *UnmarshalError) {
+	switch e := err.(type) { //nolint:errorlint // walking the tree of wrapped errors.
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			collectFieldErrors(inner, uerr)
+		}
+	case *mapstructure.DecodeError:
+		switch e.Unwrap().(type) { //nolint:errorlint // walking the tree of wrapped errors.
+		case *mapstructure.DecodeError, interface{ Unwrap() []error }:
+			collectFieldErrors(e.Unwrap(), uerr)
+		default:
+			uerr.Fields = append(uerr.Fields, FieldError{Field: e.Name(), Err: e.Unwrap()})
+		}
+	default:
+		if inner := errors.Unwrap(err); inner != nil {
+			collectFieldErrors(inner, uerr)
+
+			return
+		}
+
+		uerr.Fields = append(uerr.Fields, FieldError{Err: err})
+	}
+}
@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+type testTyping struct {
+	Bool        bool          `config:"bool"`
+	Duration    time.Duration `config:"duration"`
+	Float64     float64       `config:"float64"`
+	Int         int           `config:"int"`
+	IntSlice    []int         `config:"intslice"`
+	String      string        `config:"string"`
+	StringSlice []string      `config:"stringslice"`
+}
+
+type testSettings struct {
+	Typing testTyping `config:"typing"`
+	Server struct {
+		Address string `config:"address"`
+		IP      net.IP `config:"ip"`
+	} `config:"server"`
+}
+
+func TestViper_Unmarshal(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+	vcfg.SetString("server.ip", "127.0.0.1")
+
+	var out testSettings
+
+	if err := config.Unmarshal(vcfg, &out); err != nil {
+		t.Fatalf("config.Unmarshal(): error, got '%s', want 'nil'", err)
+	}
+
+	expect := testTyping{
+		Bool:        true,
+		Duration:    10 * time.Second,
+		Float64:     3.1415,
+		Int:         1337,
+		IntSlice:    []int{100, 200, 50},
+		String:      "foobarmoo",
+		StringSlice: []string{"one", "two", "three"},
+	}
+
+	if diff := cmp.Diff(out.Typing, expect); diff != "" {
+		t.Errorf("config.Unmarshal(): typing -got +want:\n%s", diff)
+	}
+
+	if out.Server.Address != "127.0.0.1:8080" || !out.Server.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("config.Unmarshal(): server got '%s' '%s', want '127.0.0.1:8080' '127.0.0.1'",
+			out.Server.Address, out.Server.IP,
+		)
+	}
+}
+
+func TestViper_UnmarshalKey(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+
+	var out testTyping
+
+	if err := vcfg.(*config.ViperConf).UnmarshalKey("typing", &out); err != nil {
+		t.Fatalf("config.UnmarshalKey(): error, got '%s', want 'nil'", err)
+	}
+
+	if out.Duration != 10*time.Second {
+		t.Errorf("config.UnmarshalKey(): duration got '%s', want '%s'", out.Duration, 10*time.Second)
+	}
+}
+
+func TestViper_UnmarshalReportsFields(t *testing.T) {
+	vcfg := config.NewViperConfig("test-project")
+	vcfg.SetString("typing.int", "abc")
+	vcfg.SetString("typing.duration", "ten seconds")
+
+	var out testSettings
+
+	err := config.Unmarshal(vcfg, &out)
+
+	var uerr *config.UnmarshalError
+	if !errors.As(err, &uerr) {
+		t.Fatalf("config.Unmarshal(): error, got '%v', want config.UnmarshalError", err)
+	}
+
+	fields := make([]string, 0, len(uerr.Fields))
+	for _, field := range uerr.Fields {
+		fields = append(fields, field.Field)
+	}
+
+	if diff := cmp.Diff(fields, []string{"typing.duration", "typing.int"}); diff != "" {
+		t.Errorf("config.Unmarshal(): failed fields -got +want:\n%s", diff)
+	}
+}
@@ -0,0 +1,345 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrRequired is returned when a required key has no value.
+	ErrRequired = errors.New("required key is not set")
+
+	// ErrOutOfRange is returned when a value is outside of the allowed range.
+	ErrOutOfRange = errors.New("value out of range")
+
+	// ErrNotAllowed is returned when a value does not match the allowed values or pattern.
+	ErrNotAllowed = errors.New("value not allowed")
+
+	// ErrInvalidFormat is returned when a value is not a valid URL, host:port or CIDR.
+	ErrInvalidFormat = errors.New("invalid format")
+)
+
+// Rule checks the value of a key, returning an error describing why the value is invalid.
+type Rule func(val interface{}) error
+
+// Min requires a number to be at least limit.
+func Min(limit float64) Rule {
+	return func(val interface{}) error {
+		n, err := cast.ToFloat64E(val)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+		}
+
+		if n < limit {
+			return fmt.Errorf("%w: %v is less than %v", ErrOutOfRange, n, limit)
+		}
+
+		return nil
+	}
+}
+
+// Max requires a number to be at most limit.
+func Max(limit float64) Rule {
+	return func(val interface{}) error {
+		n, err := cast.ToFloat64E(val)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+		}
+
+		if n > limit {
+			return fmt.Errorf("%w: %v is greater than %v", ErrOutOfRange, n, limit)
+		}
+
+		return nil
+	}
+}
+
+// MinDuration requires a duration to be at least limit.
+func MinDuration(limit time.Duration) Rule {
+	return func(val interface{}) error {
+		d, err := cast.ToDurationE(val)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+		}
+
+		if d < limit {
+			return fmt.Errorf("%w: %s is less than %s", ErrOutOfRange, d, limit)
+		}
+
+		return nil
+	}
+}
+
+// MaxDuration requires a duration to be at most limit.
+func MaxDuration(limit time.Duration) Rule {
+	return func(val interface{}) error {
+		d, err := cast.ToDurationE(val)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+		}
+
+		if d > limit {
+			return fmt.Errorf("%w: %s is greater than %s", ErrOutOfRange, d, limit)
+		}
+
+		return nil
+	}
+}
+
+// Match requires a string to match the regular expression, Match panics if the expression is invalid.
+func Match(pattern string) Rule {
+	re := regexp.MustCompile(pattern)
+
+	return func(val interface{}) error {
+		if s := cast.ToString(val); !re.MatchString(s) {
+			return fmt.Errorf("%w: \"%s\" does not match %s", ErrNotAllowed, s, pattern)
+		}
+
+		return nil
+	}
+}
+
+// OneOf requires a string to be one of the values.
+func OneOf(values ...string) Rule {
+	return func(val interface{}) error {
+		if s := cast.ToString(val); !slices.Contains(values, s) {
+			return fmt.Errorf("%w: \"%s\" is not one of %s", ErrNotAllowed, s, strings.Join(values, ", "))
+		}
+
+		return nil
+	}
+}
+
+// IsURL requires a string to be an absolute URL, with one of the schemes if any are supplied.
+func IsURL(schemes ...string) Rule {
+	return func(val interface{}) error {
+		s := cast.ToString(val)
+
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w: \"%s\" is not an absolute URL", ErrInvalidFormat, s)
+		}
+
+		if len(schemes) > 0 && !slices.Contains(schemes, u.Scheme) {
+			return fmt.Errorf("%w: \"%s\" scheme is not one of %s", ErrInvalidFormat, s, strings.Join(schemes, ", "))
+		}
+
+		return nil
+	}
+}
+
+// IsHostPort requires a string to be a host:port address with a valid port number.
+func IsHostPort() Rule {
+	return func(val interface{}) error {
+		s := cast.ToString(val)
+
+		_, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Errorf("%w: \"%s\" is not a host:port address", ErrInvalidFormat, s)
+		}
+
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || (n == 0 && port != "0") {
+			return fmt.Errorf("%w: \"%s\" does not have a valid port", ErrInvalidFormat, s)
+		}
+
+		return nil
+	}
+}
+
+// IsCIDR requires a string to be an IP network in CIDR notation, eg. 10.0.0.0/8.
+func IsCIDR() Rule {
+	return func(val interface{}) error {
+		s := cast.ToString(val)
+		if _, err := netip.ParsePrefix(s); err != nil {
+			return fmt.Errorf("%w: \"%s\" is not a CIDR network", ErrInvalidFormat, s)
+		}
+
+		return nil
+	}
+}
+
+// crossCheck is a rule that spans several keys, its violations are reported against the key.
+type crossCheck struct {
+	key string
+	fn  func(c Conf) error
+}
+
+// AddCheck adds a rule that checks several keys together, eg. that a maximum is greater than a minimum.
+// Any error returned by fn is reported against the key.
+func (r *Registry) AddCheck(key string, fn func(c Conf) error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checks = append(r.checks, crossCheck{key: strings.ToLower(key), fn: fn})
+}
+
+// secretError hides the message of an error about a secret value, which may include the value.
+type secretError struct {
+	err error
+}
+
+func (e secretError) Error() string {
+	return "invalid secret value"
+}
+
+func (e secretError) Unwrap() error {
+	return e.err
+}
+
+// Violation describes a single key that failed validation.
+type Violation struct {
+	// Key is the key that failed validation.
+	Key string
+	// Source describes where the value came from, eg. file /etc/project/project.toml:3, empty if unknown.
+	Source string
+	// Err is the reason the value is invalid.
+	Err error
+}
+
+func (v Violation) Error() string {
+	if v.Source != "" {
+		return fmt.Sprintf("%s: %s (from %s)", v.Key, v.Err, v.Source)
+	}
+
+	return fmt.Sprintf("%s: %s", v.Key, v.Err)
+}
+
+func (v Violation) Unwrap() error {
+	return v.Err
+}
+
+// ValidationError is returned when one or more keys fail validation.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Error())
+	}
+
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		errs = append(errs, v)
+	}
+
+	return errs
+}
+
+// applySetting converts the value of the key and applies it, recording any error wrapped in sentinel against
+// the key.
+func applySetting[T any](c Conf, key string, verr *ValidationError, sentinel error, apply func(T) error) {
+	if !c.IsSet(key) {
+		return
+	}
+
+	val, err := Get[T](c, key)
+	if err == nil {
+		err = apply(val)
+	}
+
+	if err != nil {
+		verr.Violations = append(verr.Violations, Violation{Key: key, Err: fmt.Errorf("%w: %w", sentinel, err)})
+	}
+}
+
+// Validate checks the configuration against the registered keys, returning a ValidationError listing
+// every required key that is not set, value that can not be converted to the type of its key, value that
+// breaks one of the rules of its key and failed check.
+func Validate(c Conf, r *Registry) error {
+	return validate(c, r, func(string) string { return "" })
+}
+
+// Validate checks the configuration against the keys in the registry, see Validate.
+// Each violation includes the file (or other source) that the invalid value came from.
+func (v *ViperConf) Validate() error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	return v.validate()
+}
+
+// validate checks the current snapshot against the registry, it must be called with the lock held.
+func (v *ViperConf) validate() error {
+	if v.registry == nil {
+		return nil
+	}
+
+	layers := v.layers(true)
+
+	return validate(v.snapshot(), v.registry, func(key string) string {
+		if o := explainKey(layers, key).Origin; o.Kind != "" {
+			return o.String()
+		}
+
+		return ""
+	})
+}
+
+// validate checks c against the registry, using source to describe where the value of a key came from.
+func validate(c Conf, r *Registry, source func(key string) string) error {
+	verr := &ValidationError{}
+
+	add := func(key string, err error) {
+		if r.IsSecret(key) && !errors.Is(err, ErrRequired) {
+			err = secretError{err: err}
+		}
+
+		verr.Violations = append(verr.Violations, Violation{Key: key, Source: source(key), Err: err})
+	}
+
+	for _, key := range r.Keys() {
+		if !c.IsSet(key.Name) {
+			if key.Required {
+				add(key.Name, ErrRequired)
+			}
+
+			continue
+		}
+
+		val := c.Get(key.Name)
+
+		if err := key.Type.check(val); err != nil {
+			add(key.Name, fmt.Errorf("invalid %s: %w", key.Type, err))
+
+			continue
+		}
+
+		for _, rule := range key.Rules {
+			if err := rule(val); err != nil {
+				add(key.Name, err)
+			}
+		}
+	}
+
+	r.lock.Lock()
+	checks := slices.Clone(r.checks)
+	r.lock.Unlock()
+
+	for _, chk := range checks {
+		if err := chk.fn(c); err != nil {
+			add(chk.key, err)
+		}
+	}
+
+	if len(verr.Violations) > 0 {
+		return verr
+	}
+
+	return nil
+}
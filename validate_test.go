@@ -0,0 +1,139 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+)
+
+func validationRegistry(t *testing.T) *config.Registry {
+	t.Helper()
+
+	r, err := config.NewRegistry(
+		config.Key{Name: "server.address", Type: config.TypeString, Rules: []config.Rule{config.IsHostPort()}},
+		config.Key{Name: "server.port", Type: config.TypeInt, Rules: []config.Rule{config.Min(1), config.Max(65535)}},
+		config.Key{Name: "server.timeout", Type: config.TypeDuration, Rules: []config.Rule{config.MinDuration(time.Second)}},
+		config.Key{Name: "server.name", Type: config.TypeString, Rules: []config.Rule{config.Match(`^[a-z]+$`)}},
+		config.Key{Name: "server.allow", Type: config.TypeString, Rules: []config.Rule{config.IsCIDR()}},
+		config.Key{Name: "log.level", Type: config.TypeString, Rules: []config.Rule{config.OneOf("debug", "info")}},
+		config.Key{Name: "upstream.url", Type: config.TypeString, Rules: []config.Rule{config.IsURL("https")}},
+		config.Key{Name: "database.password", Type: config.TypeString, Required: true, Secret: true},
+		config.Key{Name: "pool.min", Type: config.TypeInt},
+		config.Key{Name: "pool.max", Type: config.TypeInt},
+	)
+	if err != nil {
+		t.Fatalf("config.NewRegistry(): error, got '%s', want 'nil'", err)
+	}
+
+	r.AddCheck("pool.max", func(c config.Conf) error {
+		if c.GetInt("pool.max") < c.GetInt("pool.min") {
+			return fmt.Errorf("%w: must not be less than pool.min", config.ErrOutOfRange)
+		}
+
+		return nil
+	})
+
+	return r
+}
+
+func TestValidate_Violations(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, `[server]
+address = "localhost"
+port = 70000
+timeout = "10ms"
+name = "Server1"
+allow = "10.0.0.0"
+
+[log]
+level = "trace"
+
+[upstream]
+url = "http://example.com"
+
+[pool]
+min = 10
+max = 5
+`)
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename), config.WithRegistry(validationRegistry(t)))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	err = vcfg.(*config.ViperConf).Validate()
+
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("config.Validate(): error, got '%v', want ValidationError", err)
+	}
+
+	keys := []string{}
+	for _, v := range verr.Violations {
+		keys = append(keys, v.Key)
+	}
+
+	if diff := cmp.Diff(keys, []string{
+		"database.password", "log.level", "server.address", "server.allow", "server.name",
+		"server.port", "server.timeout", "upstream.url", "pool.max",
+	}); diff != "" {
+		t.Errorf("config.Validate(): violations -got +want:\n%s", diff)
+	}
+
+	if !errors.Is(err, config.ErrRequired) || !errors.Is(err, config.ErrOutOfRange) || !errors.Is(err, config.ErrInvalidFormat) {
+		t.Errorf("config.Validate(): error does not wrap the rule errors: %s", err)
+	}
+
+	if want := "server.port: value out of range: 70000 is greater than 65535 (from file " + filename + ":3)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("config.Validate(): error, got '%s', want to contain '%s'", err, want)
+	}
+}
+
+func TestValidate_SecretValueHidden(t *testing.T) {
+	r := validationRegistry(t)
+
+	vcfg := config.NewMemoryConf(map[string]interface{}{
+		"database": map[string]interface{}{"password": []string{"hunter2"}},
+	})
+
+	err := config.Validate(vcfg, r)
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("config.Validate(): error, got '%v', want error without the secret value", err)
+	}
+}
+
+func TestValidate_LoadAndReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[server]\nport = 0\n")
+
+	opts := []config.Option{
+		config.WithConfigFiles(filename),
+		config.WithRegistry(validationRegistry(t)),
+		config.WithValidation(),
+	}
+
+	if _, err := config.New("test", opts...); !errors.Is(err, config.ErrOutOfRange) {
+		t.Fatalf("config.New(): error, got '%v', want '%s'", err, config.ErrOutOfRange)
+	}
+
+	writeTestFile(t, filename, "[server]\nport = 8080\n[database]\npassword = 'secret'\n")
+
+	vcfg, err := config.New("test", opts...)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	writeTestFile(t, filename, "[server]\nport = 80800\n[database]\npassword = 'secret'\n")
+
+	if err = vcfg.(*config.ViperConf).Reload(); !errors.Is(err, config.ErrOutOfRange) {
+		t.Errorf("config.Reload(): error, got '%v', want '%s'", err, config.ErrOutOfRange)
+	}
+
+	expectGetInt(t, vcfg, "server.port", 8080)
+}
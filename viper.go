@@ -1,137 +1,323 @@
 package config
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/na4ma4/go-permbits"
 	"github.com/pelletier/go-toml"
-	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 // ViperConf is a Conf compatible Viper configuration object.
+//
+// Reads are lock-free, they see the most recent immutable Snapshot of the effective configuration,
+// while writes are serialised and publish a new Snapshot.
+//
+// The configuration is made up of layers that are merged in order, with later layers taking precedence:
+// defaults, the main config file, the conf.d drop-ins (if a conf.d directory is used), environment variables
+// (if an environment prefix is used), secret files (if a secrets directory is used) and finally values set
+// at runtime.
 type ViperConf struct {
-	viper    *viper.Viper
-	lock     *sync.Mutex
-	filename string
+	lock       *sync.Mutex
+	format     string
+	saveFormat string
+	filename   string
+	configFile string
+	confdpath  string
+	skipConfD  bool
+	project    string
+	envPrefix  string
+	secretsDir string
+	fileMode   fs.FileMode
+	defaults   map[string]interface{}
+	file       layer
+	dropins    []layer
+	secrets    layer
+	overrides  map[string]interface{}
+	// replaced lists the sections of the overrides that were unset and then written to, they replace the
+	// sections in the lower layers rather than being merged into them.
+	replaced   []string
+	current    atomic.Pointer[Snapshot]
+	onChange   []ChangeFunc
+	onError    []func(error)
+	registry   *Registry
+	validating bool
+	report     LoadReport
+	sliceMerge SliceMerge
+	level      *zap.AtomicLevel
+	slogLevel  *slog.LevelVar
 }
 
 // NewViperConfigFromViper returns a Conf compatible ViperConf object copied from the system viper.Viper.
 func NewViperConfigFromViper(vcfg *viper.Viper, filename ...string) Conf {
-	allset := vcfg.AllSettings()
+	return newViperConfFromViper(vcfg, "", filename...)
+}
+
+// NewViperConfig returns a Conf compatible ViperConf object.
+//
+// Config files that are found but can not be loaded are skipped, LoadReport describes any errors.
+func NewViperConfig(project string, filename ...string) Conf {
+	v, _ := loadViperConf(legacyOptions(project, "", false, filename))
+
+	return v
+}
+
+// LoadViperConfig returns a Conf compatible ViperConf object, failing on the first config file that is found
+// but can not be loaded.
+func LoadViperConfig(project string, filename ...string) (Conf, error) {
+	v, err := loadViperConf(legacyOptions(project, "", true, filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func newViperConf(o *options) *ViperConf {
 	v := &ViperConf{
-		viper:    viper.New(),
-		lock:     &sync.Mutex{},
-		filename: vcfg.ConfigFileUsed(),
+		lock:       &sync.Mutex{},
+		format:     o.format,
+		saveFormat: o.saveFormat,
+		confdpath:  o.confdpath,
+		project:    o.project,
+		fileMode:   o.fileMode,
+		defaults:   map[string]interface{}{},
+		overrides:  map[string]interface{}{},
 	}
 
-	for key, val := range allset {
-		v.Set(key, val)
+	if o.envEnabled {
+		v.envPrefix = envPrefix(cmp.Or(o.envPrefix, o.project))
 	}
 
-	if len(filename) > 0 {
-		v.filename = filepath.Clean(os.ExpandEnv(filename[0]))
+	if o.registry != nil {
+		v.registry = o.registry
+		mergeSettings(v.defaults, o.registry.defaults())
+	}
+
+	v.validating = o.validate
+	v.sliceMerge = o.sliceMerge
+
+	if o.secretsEnabled {
+		v.secretsDir = cmp.Or(o.secretsDir, DefaultSecretsDir)
 	}
 
 	return v
 }
 
-// NewViperConfig returns a Conf compatible ViperConf object.
-func NewViperConfig(project string, filename ...string) Conf {
+func newViperConfFromViper(vcfg *viper.Viper, confdpath string, filename ...string) *ViperConf {
+	v := newViperConf(&options{format: "toml", confdpath: confdpath})
+	v.filename = vcfg.ConfigFileUsed()
+	v.overrides = copySettings(vcfg.AllSettings())
+
 	if len(filename) > 0 {
-		for i, fname := range filename {
-			v := &ViperConf{
-				viper:    viper.New(),
-				lock:     &sync.Mutex{},
-				filename: fname,
-			}
-			err := v.readFromFile(project, fname)
+		v.filename = filepath.Clean(os.ExpandEnv(filename[0]))
+	}
 
-			if i == len(filename)-1 {
-				// If filenames are specified, the last one is used as the fallback
-				// and is then used for the `Save()` method.
-				v.setFilename(fname)
+	v.configFile = v.filename
 
-				return v
-			}
+	_ = v.loadConfigPath(false)
 
-			// Error loading file, and not the last filename in the list
-			if err != nil {
-				continue
-			}
+	v.rebuild()
 
-			// No error, so the file was loaded successfully
-			v.filename = v.viper.ConfigFileUsed()
-			if v.viper.ConfigFileUsed() == "" {
-				continue
-			}
+	return v
+}
+
+// loadViperConf returns a ViperConf loaded from the first config file found and the conf.d directory.
+func loadViperConf(o *options) (*ViperConf, error) {
+	v := newViperConf(o)
+
+	file, report, err := o.readInConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	v.file = file
+	v.report = report
+	v.filename = o.filename(report)
+	v.configFile = cmp.Or(report.ConfigFile, v.filename)
 
-			return v
+	v.skipConfD = o.skipConfD(report)
+
+	if !v.skipConfD {
+		if err = v.loadConfigPath(o.failFast); err != nil {
+			return nil, err
 		}
 	}
 
-	fname := project + ".toml"
-	v := &ViperConf{
-		viper:    viper.New(),
-		lock:     &sync.Mutex{},
-		filename: fname,
+	if errs := v.loadSecrets(); len(errs) > 0 && o.failFast {
+		return nil, errs[0]
 	}
-	v.initConfig(project)
 
-	if !strings.EqualFold(v.viper.ConfigFileUsed(), "") {
-		v.filename = v.viper.ConfigFileUsed()
+	v.rebuild()
+
+	if v.validating {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	return v
+	return v, nil
 }
 
-func (v *ViperConf) readFromFile(project, filename string) error {
-	v.lock.Lock()
-	defer v.lock.Unlock()
+// loadConfigPath merges every drop-in in the conf.d directory in lexical order, recording each file
+// merged or failed in the load report. Unless failFast is set, files that fail are skipped.
+func (v *ViperConf) loadConfigPath(failFast bool) error {
+	dropins, errs, err := readConfigPath(v.confdpath, failFast)
+	if err != nil {
+		return err
+	}
 
-	v.viper.SetConfigName(project)
-	v.viper.SetConfigType("toml")
-	v.viper.SetConfigFile(filename)
+	v.dropins = dropins
+	v.report.Errors = append(v.report.Errors, errs...)
 
-	if err := v.viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("unable to read in config: %w", err)
+	for _, dropin := range dropins {
+		v.report.DropIns = append(v.report.DropIns, dropin.source)
 	}
 
 	return nil
 }
 
-func (v *ViperConf) setFilename(filename string) {
+// confDir returns the conf.d directory that is merged, empty if the legacy constructors skipped it when
+// falling back to the last filename.
+func (v *ViperConf) confDir() string {
+	if v.skipConfD {
+		return ""
+	}
+
+	return v.confdpath
+}
+
+// loadSecrets reads the secret files into the secrets layer, recording any that could not be read
+// in the load report. It must be called with the lock held after the file layers have been loaded.
+func (v *ViperConf) loadSecrets() []*FileError {
+	v.secrets = layer{}
+
+	if v.secretsDir == "" {
+		return nil
+	}
+
+	base := mergeLayers(v.layers(false)...)
+
+	var errs []*FileError
+	v.secrets, errs = readSecrets(v.secretsDir, v.envPrefix, base)
+	v.report.Errors = append(v.report.Errors, errs...)
+
+	return errs
+}
+
+// layers returns each layer in merge order. The environment and secrets layers are only included
+// when withExternal is set, as they are never written back to the config file.
+func (v *ViperConf) layers(withExternal bool) []layer {
+	layers := make([]layer, 0, len(v.dropins)+5) //nolint:mnd // defaults, file, env, secrets and overrides.
+	layers = append(layers, layer{kind: SourceDefault, settings: v.defaults})
+
+	file := v.file
+	file.kind = SourceFile
+	layers = append(layers, file)
+
+	for _, dropin := range v.dropins {
+		dropin.kind = SourceDropIn
+		dropin.sliceMerge = cmp.Or(dropin.sliceMerge, v.sliceMerge)
+		layers = append(layers, dropin)
+	}
+
+	if withExternal && v.envPrefix != "" {
+		layers = append(layers, envLayer(v.envPrefix, mergeLayers(layers...)))
+	}
+
+	if withExternal && v.secrets.settings != nil {
+		layers = append(layers, v.secrets)
+	}
+
+	return append(layers, layer{kind: SourceOverride, settings: v.overrides, replace: v.replaced})
+}
+
+// rebuild merges the layers into a new snapshot of the effective settings, it must be called with the lock
+// held after any layer has changed. Snapshots are never modified once stored, so readers do not need the lock.
+func (v *ViperConf) rebuild() {
+	layers := v.layers(true)
+	s := newSnapshot(mergeLayers(layers...))
+
+	// The first layer holds the defaults.
+	if len(v.defaults) > 0 {
+		s.explicit = mergeLayers(layers[1:]...)
+	}
+
+	v.store(s)
+}
+
+// store publishes the snapshot and updates the zap and slog levels to match, it must be called with the lock held.
+func (v *ViperConf) store(s *Snapshot) {
+	v.current.Store(s)
+
+	if v.level == nil && v.slogLevel == nil {
+		return
+	}
+
+	level := zapLevel(s)
+
+	if v.level != nil {
+		v.level.SetLevel(level)
+	}
+
+	if v.slogLevel != nil {
+		v.slogLevel.Set(slogLevel(level))
+	}
+}
+
+// explicitSettings returns the settings that are not defaults, the result must not be modified.
+func (v *ViperConf) explicitSettings() map[string]interface{} {
+	return v.snapshot().explicitSettings()
+}
+
+// set sets the value for the key in the override layer.
+func (v *ViperConf) set(key string, value interface{}) {
 	v.lock.Lock()
-	v.filename = filename
-	v.viper.SetConfigType("toml")
-	v.viper.SetConfigFile(filename)
-	v.lock.Unlock()
+	defer v.lock.Unlock()
+
+	// Writing through an unset section must not bring back the keys hidden by unsetting it.
+	path := splitKey(key)
+	for i := range path {
+		section := strings.Join(path[:i+1], keyDelimiter)
+		if val, ok := lookupKey(v.overrides, section); ok {
+			if _, unset := val.(unsetValue); unset && !slices.Contains(v.replaced, section) {
+				v.replaced = append(v.replaced, section)
+			}
+		}
+	}
+
+	setKey(v.overrides, key, value)
+	v.rebuild()
 }
 
-func (v *ViperConf) initConfig(project string) {
+// saveOverride returns a function that restores the override layer for the key to its current state.
+func (v *ViperConf) saveOverride(key string) func() {
 	v.lock.Lock()
 	defer v.lock.Unlock()
 
-	v.viper.SetConfigName(project)
-	v.viper.SetConfigType("toml")
-	v.viper.AddConfigPath("./artifacts")
-	v.viper.AddConfigPath("./test")
-	v.viper.AddConfigPath("./testdata")
-	v.viper.AddConfigPath("$HOME/.config")
-	v.viper.AddConfigPath("/etc")
-	v.viper.AddConfigPath("/etc/" + project)
-	v.viper.AddConfigPath("/usr/local/" + project + "/etc")
-	v.viper.AddConfigPath("/run/secrets")
-	v.viper.AddConfigPath(".")
+	saved, replaced := saveKey(v.overrides, key), slices.Clone(v.replaced)
+
+	return func() {
+		v.lock.Lock()
+		defer v.lock.Unlock()
 
-	_ = v.viper.ReadInConfig()
+		saved.restore(v.overrides)
+		v.replaced = replaced
+		v.rebuild()
+	}
 }
 
 // SetDefault sets the default value for this key.
@@ -140,177 +326,253 @@ func (v *ViperConf) initConfig(project string) {
 func (v *ViperConf) SetDefault(key string, value interface{}) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
-	v.viper.SetDefault(key, value)
+
+	setKey(v.defaults, key, value)
+	v.rebuild()
 }
 
-// Get can retrieve any value given the key to use.
-// Get is case-insensitive for a key.
-// Get has the behavior of returning the value associated with the first
-// place from where it is set. Viper will check in the following order:
-// override, flag, env, config file, key/value store, default
-//
-// Get returns an interface. For a specific value use one of the Get____ methods.
-func (v *ViperConf) Get(key string) interface{} {
+// SetRegistry sets the registry of keys the configuration understands, see WithRegistry.
+// The defaults of the registered keys are merged over any existing defaults.
+func (v *ViperConf) SetRegistry(r *Registry) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
-	val := v.viper.Get(key)
 
-	return val
+	v.registry = r
+	mergeSettings(v.defaults, r.defaults())
+	v.rebuild()
 }
 
-// GetBool returns the value associated with the key as a boolean.
-func (v *ViperConf) GetBool(key string) bool {
+// Registry returns the registry of keys the configuration understands, nil if none has been set.
+func (v *ViperConf) Registry() *Registry {
 	v.lock.Lock()
 	defer v.lock.Unlock()
-	val := v.viper.GetBool(key)
 
-	return val
+	return v.registry
 }
 
-// GetDuration returns the value associated with the key as a duration.
-func (v *ViperConf) GetDuration(key string) time.Duration {
+// AllSettings merges all settings and returns them as a map[string]interface{}.
+func (v *ViperConf) AllSettings() map[string]interface{} {
+	return v.snapshot().AllSettings()
+}
+
+// IsSet returns true if the key has a value in any layer, including defaults.
+// IsSet is case-insensitive for a key.
+func (v *ViperConf) IsSet(key string) bool {
+	return v.snapshot().IsSet(key)
+}
+
+// Unset removes the key (or section) from the configuration, hiding any value from the config files
+// and defaults, so that the next Save drops it from the file. Unset keys stay unset across reloads
+// until they are set again, setting a key within an unset section does not bring back the rest of it.
+func (v *ViperConf) Unset(key string) {
+	v.set(key, unsetValue{})
+}
+
+// SetEnvPrefix enables reading values from environment variables named with the prefix, see WithEnvPrefix.
+// If the prefix is empty, the project name is used.
+func (v *ViperConf) SetEnvPrefix(prefix string) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
-	val := v.viper.GetDuration(key)
 
-	return val
+	v.envPrefix = envPrefix(cmp.Or(prefix, v.project))
+	v.loadSecrets()
+	v.rebuild()
 }
 
-// GetFloat64 returns the value associated with the key as a float64.
-func (v *ViperConf) GetFloat64(key string) float64 {
+// SetSecretsDir enables reading secret files from the directory, see WithSecretsDir.
+// If the directory is empty, DefaultSecretsDir is used.
+func (v *ViperConf) SetSecretsDir(dir string) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
-	val := v.viper.GetFloat64(key)
 
-	return val
+	v.secretsDir = cmp.Or(dir, DefaultSecretsDir)
+	v.loadSecrets()
+	v.rebuild()
+}
+
+// AllKeys returns every key that has a value, sorted.
+func (v *ViperConf) AllKeys() []string {
+	return v.snapshot().AllKeys()
+}
+
+// Get can retrieve any value given the key to use.
+// Get is case-insensitive for a key.
+// Get has the behavior of returning the value associated with the first
+// place from where it is set. The layers are checked in the following order:
+// override, env, conf.d, config file, default
+//
+// Get returns an interface. For a specific value use one of the Get____ methods.
+func (v *ViperConf) Get(key string) interface{} {
+	return v.snapshot().Get(key)
+}
+
+// GetBool returns the value associated with the key as a boolean.
+func (v *ViperConf) GetBool(key string) bool {
+	return v.snapshot().GetBool(key)
+}
+
+// GetDuration returns the value associated with the key as a duration.
+func (v *ViperConf) GetDuration(key string) time.Duration {
+	return v.snapshot().GetDuration(key)
+}
+
+// GetFloat64 returns the value associated with the key as a float64.
+func (v *ViperConf) GetFloat64(key string) float64 {
+	return v.snapshot().GetFloat64(key)
 }
 
 // GetInt returns the value associated with the key as an int.
 func (v *ViperConf) GetInt(key string) int {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	val := v.viper.GetInt(key)
-
-	return val
+	return v.snapshot().GetInt(key)
 }
 
 // GetIntSlice returns the value associated with the key as a slice of ints.
+// A comma separated string is split into its items.
 func (v *ViperConf) GetIntSlice(key string) []int {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	val := cast.ToIntSlice(v.viper.Get(key))
-
-	return val
+	return v.snapshot().GetIntSlice(key)
 }
 
 // GetString returns the value associated with the key as a string.
 func (v *ViperConf) GetString(key string) string {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	val := v.viper.GetString(key)
-
-	return val
+	return v.snapshot().GetString(key)
 }
 
 // GetStringSlice returns the value associated with the key as a slice of strings.
+// A comma separated string is split into its items.
 func (v *ViperConf) GetStringSlice(key string) []string {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	val := v.viper.GetStringSlice(key)
-
-	return val
+	return v.snapshot().GetStringSlice(key)
 }
 
-// Set sets the value for the key in the viper object.
+// Set sets the value for the key in the override layer.
 func (v *ViperConf) Set(key string, value interface{}) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetBool sets the value for the key in the viper object.
+// SetBool sets the value for the key in the override layer.
 func (v *ViperConf) SetBool(key string, value bool) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetDuration sets the value for the key in the viper object.
+// SetDuration sets the value for the key in the override layer.
 func (v *ViperConf) SetDuration(key string, value time.Duration) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetFloat64 sets the value for the key in the viper object.
+// SetFloat64 sets the value for the key in the override layer.
 func (v *ViperConf) SetFloat64(key string, value float64) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetInt sets the value for the key in the viper object.
+// SetInt sets the value for the key in the override layer.
 func (v *ViperConf) SetInt(key string, value int) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetIntSlice sets the value for the key in the viper object.
+// SetIntSlice sets the value for the key in the override layer.
 func (v *ViperConf) SetIntSlice(key string, value []int) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetString sets the value for the key in the viper object.
+// SetString sets the value for the key in the override layer.
 func (v *ViperConf) SetString(key string, value string) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// SetStringSlice sets the value for the key in the viper object.
+// SetStringSlice sets the value for the key in the override layer.
 func (v *ViperConf) SetStringSlice(key string, value []string) {
-	v.lock.Lock()
-	defer v.lock.Unlock()
-	v.viper.Set(key, value)
+	v.set(key, value)
 }
 
-// Save writes the config to the file system.
+// Save writes the config to the file system, in the save format if one has been set or the format
+// matching the file extension. Values from environment variables and secret files are not written.
+//
+// The file is replaced atomically, keeping the mode and ownership of the existing file unless
+// a file mode has been set.
 func (v *ViperConf) Save() error {
 	v.lock.Lock()
 	defer v.lock.Unlock()
 
-	if err := os.MkdirAll(filepath.Dir(v.filename), permbits.MustString("u=rwx,g=rx")); err != nil {
-		return fmt.Errorf("unable to create directory: %w", err)
-	}
-
-	if _, err := os.Create(v.filename); err != nil {
-		return fmt.Errorf("unable to create file: %w", err)
+	data, err := encodeSettings(mergeLayers(v.layers(false)...), v.outputFormat())
+	if err != nil {
+		return err
 	}
 
-	if err := v.viper.WriteConfigAs(v.filename); err != nil {
+	if err = writeFileAtomic(v.filename, data, v.fileMode); err != nil {
 		return fmt.Errorf("unable to write out config: %w", err)
 	}
 
 	return nil
 }
 
+// SetFileMode sets the mode that Save creates the config file with, eg. 0600 for files holding credentials.
+// A mode of zero keeps the mode of the existing file.
+func (v *ViperConf) SetFileMode(mode fs.FileMode) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.fileMode = mode
+}
+
+// SetSliceMerge sets the strategy used to merge lists in conf.d drop-ins with the lists they override, see
+// WithSliceMerge.
+func (v *ViperConf) SetSliceMerge(strategy SliceMerge) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.sliceMerge = strategy
+	v.rebuild()
+}
+
+// SetSaveFormat sets the format (toml, yaml or json) that Save and Write use, regardless of the file extension.
+// An empty format uses the format matching the file extension.
+func (v *ViperConf) SetSaveFormat(format string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.saveFormat = strings.ToLower(format)
+}
+
+// outputFormat returns the format that Save and Write use.
+func (v *ViperConf) outputFormat() string {
+	return cmp.Or(v.saveFormat, formatForFile(v.filename, v.format))
+}
+
+// Write writes the config to out in the format that Save uses, values from environment variables and
+// secret files are not written.
 func (v *ViperConf) Write(out io.Writer) error {
+	v.lock.Lock()
+	format := v.outputFormat()
+	v.lock.Unlock()
+
+	return v.WriteFormat(out, format)
+}
+
+// WriteFormat writes the config to out in the format (toml, yaml or json), values from environment
+// variables and secret files are not written.
+func (v *ViperConf) WriteFormat(out io.Writer, format string) error {
 	v.lock.Lock()
 	defer v.lock.Unlock()
 
-	c := v.viper.AllSettings()
+	settings := mergeLayers(v.layers(false)...)
 
-	t, err := toml.TreeFromMap(c)
-	if err != nil {
-		return fmt.Errorf("unable to make tree from map: %w", err)
-	}
+	var s string
 
-	s := t.String()
+	switch strings.ToLower(format) {
+	case "toml":
+		t, err := toml.TreeFromMap(settings)
+		if err != nil {
+			return fmt.Errorf("unable to make tree from map: %w", err)
+		}
 
-	if _, err = io.WriteString(out, s); err != nil {
+		s = t.String()
+	default:
+		data, err := encodeSettings(settings, strings.ToLower(format))
+		if err != nil {
+			return err
+		}
+
+		s = string(data)
+	}
+
+	if _, err := io.WriteString(out, s); err != nil {
 		return fmt.Errorf("unable to write config file: %w", err)
 	}
 
@@ -319,12 +581,120 @@ func (v *ViperConf) Write(out io.Writer) error {
 
 // ZapConfig returns a zap logger configuration derived from settings in the viper config.
 func (v *ViperConf) ZapConfig() zap.Config {
+	return v.snapshot().ZapConfig()
+}
+
+// OnChange registers a callback that is called whenever a reload changes the configuration.
+func (v *ViperConf) OnChange(fn ChangeFunc) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.onChange = append(v.onChange, fn)
+}
+
+// OnReloadError registers a callback that is called whenever a reload started by Watch fails, eg. because a
+// file can not be parsed or the new configuration is invalid, or the watcher reports an error, eg. because
+// events were missed. The existing configuration is kept.
+func (v *ViperConf) OnReloadError(fn func(error)) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
+	v.onError = append(v.onError, fn)
+}
+
+// reloadError passes the error to the OnReloadError callbacks.
+func (v *ViperConf) reloadError(err error) {
+	v.lock.Lock()
+	callbacks := slices.Clone(v.onError)
+	v.lock.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
+
+// LoadReport returns a description of the most recent attempt to load the config file and conf.d directory.
+func (v *ViperConf) LoadReport() LoadReport {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	return v.report.clone()
+}
+
+// Reload re-reads the config file that was loaded (rather than the save target), the conf.d directory
+// and any secret files, calling any OnChange callbacks if the settings have changed.
+// If any of the files can not be parsed, or validation is enabled and the new configuration is invalid,
+// the existing configuration is kept and an error is returned.
+func (v *ViperConf) Reload() error {
+	v.lock.Lock()
+
+	file, loaded, err := reloadMainFile(v.configFile, v.format)
+	if err != nil {
+		v.report.Errors = []*FileError{newFileError(v.configFile, err)}
+		v.lock.Unlock()
+
+		return err
+	}
+
+	dropins, _, err := readConfigPath(v.confDir(), true)
+	if err != nil {
+		v.report.Errors = []*FileError{newFileError(v.confDir(), err)}
+		v.lock.Unlock()
+
+		return err
+	}
+
+	old := v.snapshot()
+	oldFile, oldDropIns, oldSecrets, oldReport := v.file, v.dropins, v.secrets, v.report
+
+	v.file = file
+	v.dropins = dropins
+	v.report = LoadReport{NotFound: !loaded}
+
+	if loaded {
+		v.report.ConfigFile = v.configFile
+	}
+
+	for _, dropin := range dropins {
+		v.report.DropIns = append(v.report.DropIns, dropin.source)
+	}
 
-	if v.viper.GetBool("debug") {
-		return zap.NewDevelopmentConfig()
+	v.loadSecrets()
+	v.rebuild()
+
+	if v.validating {
+		if err = v.validate(); err != nil {
+			v.file, v.dropins, v.secrets, v.report = oldFile, oldDropIns, oldSecrets, oldReport
+			v.store(old)
+			v.lock.Unlock()
+
+			return err
+		}
+	}
+
+	oldSettings, newSettings := old.settings, v.snapshot().settings
+	callbacks := slices.Clone(v.onChange)
+	v.lock.Unlock()
+
+	if !reflect.DeepEqual(oldSettings, newSettings) {
+		for _, fn := range callbacks {
+			fn(copySettings(oldSettings), copySettings(newSettings))
+		}
 	}
 
-	return zap.NewProductionConfig()
+	return nil
+}
+
+// Watch watches the config file and the conf.d directory for changes and reloads the configuration
+// when they change, bursts of writes are debounced into a single reload.
+// Reload errors, and errors from the watcher such as missed events, are passed to any OnReloadError callbacks.
+// Watching stops when the context is cancelled.
+func (v *ViperConf) Watch(ctx context.Context) error {
+	v.lock.Lock()
+	filename, confdpath := v.configFile, v.confDir()
+	v.lock.Unlock()
+
+	return watchFiles(ctx, filename, confdpath, defaultWatchDebounce, func() {
+		if err := v.Reload(); err != nil {
+			v.reloadError(err)
+		}
+	}, v.reloadError)
 }
@@ -3,6 +3,7 @@ package config_test
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -323,3 +324,157 @@ func TestViper_ZapConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestViper_AllSettings(t *testing.T) {
+	vcfg := config.NewViperConfig("test", "testdata/test-project.toml")
+
+	v, ok := vcfg.(*config.ViperConf)
+	if !ok {
+		t.Fatal("config.AllSettings(): vcfg not config.ViperConf")
+	}
+
+	v.SetDefault("category1.default", "default")
+
+	settings := v.AllSettings()
+
+	category1, ok := settings["category1"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("config.AllSettings(): category1 got '%T', want 'map[string]interface{}'", settings["category1"])
+	}
+
+	if diff := cmp.Diff(category1["string"], "foobar"); diff != "" {
+		t.Errorf("config.AllSettings(): category1.string -got +want:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(category1["default"], "default"); diff != "" {
+		t.Errorf("config.AllSettings(): category1.default -got +want:\n%s", diff)
+	}
+}
+
+func TestViper_IsSetUnsetAllKeys(t *testing.T) {
+	vcfg := config.NewViperConfig("test", "testdata/test-project.toml")
+
+	if !vcfg.IsSet("category1.int") {
+		t.Error("config.IsSet(): category1.int got 'false', want 'true'")
+	}
+
+	if vcfg.IsSet("category2.int") {
+		t.Error("config.IsSet(): category2.int got 'true', want 'false'")
+	}
+
+	vcfg.Unset("category1.int")
+	vcfg.Unset("typing")
+
+	if vcfg.IsSet("category1.int") {
+		t.Error("config.IsSet(): unset category1.int got 'true', want 'false'")
+	}
+
+	expectGetInt(t, vcfg, "category1.int", 0)
+
+	expectKeys := []string{
+		"category1.float",
+		"category1.string",
+		"category1.strings",
+		"server.address",
+	}
+
+	if diff := cmp.Diff(vcfg.AllKeys(), expectKeys); diff != "" {
+		t.Errorf("config.AllKeys(): -got +want:\n%s", diff)
+	}
+
+	vcfg.SetInt("category1.int", 1)
+
+	expectGetInt(t, vcfg, "category1.int", 1)
+}
+
+func TestViper_UnsetMissingKeyLeavesNoSection(t *testing.T) {
+	for name, vcfg := range map[string]config.Conf{
+		"viper":  config.NewViperConfig("test", "testdata/test-project.toml"),
+		"memory": config.NewMemoryConf(map[string]interface{}{"category1": map[string]interface{}{"int": 1}}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			keys := vcfg.AllKeys()
+
+			vcfg.Unset("missing.key")
+			vcfg.Unset("category1.int.deeper")
+
+			if vcfg.IsSet("missing") {
+				t.Error("config.IsSet(): missing got 'true', want 'false'")
+			}
+
+			if diff := cmp.Diff(vcfg.AllKeys(), keys); diff != "" {
+				t.Errorf("config.AllKeys(): -got +want:\n%s", diff)
+			}
+
+			vcfg.Unset("category1.int")
+
+			if m, ok := vcfg.Get("category1").(map[string]interface{}); ok && len(m) == 0 {
+				t.Error("config.Get(): emptied category1 got an empty section, want no value")
+			}
+		})
+	}
+}
+
+func TestViper_UnsetIsDroppedBySave(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[category]\nkeep = 'foo'\nremove = 'bar'\n")
+
+	vcfg := config.NewViperConfig("test", filename)
+	vcfg.Unset("category.remove")
+
+	if err := vcfg.(*config.ViperConf).Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	if vcfg.IsSet("category.remove") {
+		t.Error("config.IsSet(): reloaded category.remove got 'true', want 'false'")
+	}
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(string(b), "[category]\nkeep = 'foo'\n"); diff != "" {
+		t.Errorf("config.Save(): config file -got +want:\n%s", diff)
+	}
+}
+
+func TestViper_SetAfterUnsetSection(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[cat]\na = 'x'\nb = 'y'\n")
+
+	vcfg := config.NewViperConfig("test", filename)
+	vcfg.Unset("cat")
+
+	if diff := cmp.Diff(vcfg.AllKeys(), []string{}); diff != "" {
+		t.Errorf("config.AllKeys(): after unset -got +want:\n%s", diff)
+	}
+
+	vcfg.SetString("cat.c", "z")
+
+	if diff := cmp.Diff(vcfg.AllKeys(), []string{"cat.c"}); diff != "" {
+		t.Errorf("config.AllKeys(): after set -got +want:\n%s", diff)
+	}
+
+	if err := vcfg.(*config.ViperConf).Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	if err := vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if diff := cmp.Diff(string(b), "[cat]\nc = 'z'\n"); diff != "" {
+		t.Errorf("config.Save(): config file -got +want:\n%s", diff)
+	}
+}
@@ -3,6 +3,7 @@ package config_test
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -332,3 +333,52 @@ func TestViperConfD_ZapConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestViperConfD_WriteIncludesDropIns(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	vcfg := config.NewViperConfD("test-project", "testdata/conf.d", "testdata/test-project.toml", "fallback.toml")
+
+	v, ok := vcfg.(*config.ViperConfD)
+	if !ok {
+		t.Fatal("config.Write(): vcfg not config.ViperConfD")
+	}
+
+	v.SetDefault("category3.first", "default")
+	v.SetDefault("category3.third", "default")
+
+	expectGetString(t, vcfg, "category3.first", "foo")
+	expectGetString(t, vcfg, "category3.third", "default")
+
+	if err := v.Write(buf); err != nil {
+		t.Fatalf("config.Write(): error, got '%s', want 'nil'", err)
+	}
+
+	for _, expect := range []string{"[category2]\n  int = 8335\n", "second = \"foobar\"", "third = \"default\""} {
+		if !bytes.Contains(buf.Bytes(), []byte(expect)) {
+			t.Errorf("config.Write(): output missing '%s', got:\n%s", expect, buf.String())
+		}
+	}
+}
+
+func TestViperConfD_ReloadFallbackSkipsConfD(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("os.Mkdir(): error, got '%s', want 'nil'", err)
+	}
+
+	fallback := filepath.Join(dir, "fallback.toml")
+	writeTestFile(t, fallback, "x = 0\n")
+	writeTestFile(t, filepath.Join(confd, "10-x.toml"), "x = 1\n")
+
+	vcfg := config.NewViperConfD("test", confd, filepath.Join(dir, "missing.toml"), fallback)
+	expectGetInt(t, vcfg, "x", 0)
+
+	if err := vcfg.(*config.ViperConfD).Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetInt(t, vcfg, "x", 0)
+}
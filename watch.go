@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ChangeFunc is called after a reload has changed the configuration,
+// it receives the settings from before and after the reload.
+type ChangeFunc func(oldSettings, newSettings map[string]interface{})
+
+// defaultWatchDebounce is how long the watcher waits for a burst of writes to settle before reloading.
+const defaultWatchDebounce = 100 * time.Millisecond
+
+// watchFiles watches the main config file and the conf.d directory, calling reload once
+// a burst of events affecting either of them has settled, until the context is cancelled.
+// Errors from the watcher (eg. fsnotify.ErrEventOverflow) are passed to onError and also schedule a reload,
+// as events may have been missed.
+func watchFiles(
+	ctx context.Context, filename, confdpath string, debounce time.Duration, reload func(), onError func(error),
+) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create file watcher: %w", err)
+	}
+
+	if filename != "" {
+		if filename, err = filepath.Abs(filename); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("unable to resolve config file \"%s\": %w", filename, err)
+		}
+
+		// Watch the directory rather than the file so editors that replace the file are noticed.
+		if err = watcher.Add(filepath.Dir(filename)); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("unable to watch config directory \"%s\": %w", filepath.Dir(filename), err)
+		}
+	}
+
+	if confdpath != "" {
+		if confdpath, err = filepath.Abs(confdpath); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("unable to resolve config path \"%s\": %w", confdpath, err)
+		}
+
+		if err = watcher.Add(confdpath); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("unable to watch config path \"%s\": %w", confdpath, err)
+		}
+	}
+
+	matches := func(name string) bool {
+		name = filepath.Clean(name)
+
+		if filename != "" && name == filename {
+			return true
+		}
+
+		return confdpath != "" && filepath.Dir(name) == confdpath && isConfigFile(name)
+	}
+
+	go func() {
+		defer func() {
+			_ = watcher.Close()
+		}()
+
+		var (
+			timer *time.Timer
+			fire  <-chan time.Time
+		)
+
+		schedule := func() {
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+				fire = timer.C
+			} else {
+				timer.Reset(debounce)
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !matches(event.Name) || event.Op == fsnotify.Chmod {
+					continue
+				}
+
+				schedule()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				onError(fmt.Errorf("unable to watch config files: %w", err))
+				schedule()
+			case <-fire:
+				timer, fire = nil, nil
+
+				reload()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// readConfigFile parses a single config file into a layer without touching the live configuration.
+// The format is detected from the file extension, falling back to format.
+func readConfigFile(filename, format string) (layer, error) {
+	format = formatForFile(filename, format)
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return layer{}, newFileError(filename, err)
+	}
+
+	staging := viper.New()
+	staging.SetConfigType(format)
+
+	if err = staging.ReadConfig(bytes.NewReader(data)); err != nil {
+		return layer{}, newFileError(filename, err)
+	}
+
+	return layer{
+		source:   filename,
+		settings: copySettings(staging.AllSettings()),
+		lines:    keyLines(data, format),
+	}, nil
+}
+
+// reloadMainFile returns the settings in filename, a missing file results in an empty layer
+// and reports that nothing was loaded.
+func reloadMainFile(filename, format string) (layer, bool, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return layer{settings: map[string]interface{}{}}, false, nil
+	}
+
+	file, err := readConfigFile(filename, format)
+	if err != nil {
+		return layer{}, false, err
+	}
+
+	return file, true, nil
+}
+
+// readConfigPath parses every drop-in in the conf.d directory in lexical order, drop-ins can be
+// in any mix of TOML, YAML and JSON.
+// Unless failFast is set, files that fail are skipped and returned as errors alongside the drop-ins.
+func readConfigPath(confdpath string, failFast bool) ([]layer, []*FileError, error) {
+	if confdpath == "" {
+		return nil, nil, nil
+	}
+
+	abspath, err := filepath.Abs(confdpath)
+	if err != nil {
+		abspath = confdpath
+	}
+
+	m, err := filepath.Glob(filepath.Join(abspath, "*"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to find config files \"%s\": %w", abspath, err)
+	}
+
+	m = slices.DeleteFunc(m, func(fn string) bool {
+		return !isConfigFile(fn)
+	})
+
+	var (
+		dropins = make([]layer, 0, len(m))
+		errs    []*FileError
+	)
+
+	for _, fn := range m {
+		dropin, readErr := readConfigFile(fn, "")
+		if readErr == nil {
+			if err = dropin.readDirectives(); err != nil {
+				readErr = newFileError(fn, err)
+			}
+		}
+
+		if readErr != nil {
+			if failFast {
+				return nil, nil, readErr
+			}
+
+			errs = append(errs, newFileError(fn, readErr))
+
+			continue
+		}
+
+		dropins = append(dropins, dropin)
+	}
+
+	return dropins, errs, nil
+}
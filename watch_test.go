@@ -0,0 +1,181 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/config"
+)
+
+func writeTestFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("os.WriteFile(): error, got '%s', want 'nil'", err)
+	}
+}
+
+func TestViper_Reload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:8080\"\n")
+
+	vcfg := config.NewViperConfig("test", filename)
+	v, ok := vcfg.(*config.ViperConf)
+	if !ok {
+		t.Fatal("config.Reload(): vcfg not config.ViperConf")
+	}
+
+	var calls int
+	v.OnChange(func(oldSettings, newSettings map[string]interface{}) {
+		calls++
+
+		if old := oldSettings["server"].(map[string]interface{})["address"]; old != "127.0.0.1:8080" {
+			t.Errorf("config.OnChange(): old value got '%v', want '127.0.0.1:8080'", old)
+		}
+	})
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:9090\"\n")
+
+	if err := v.Reload(); err != nil {
+		t.Errorf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:9090")
+
+	writeTestFile(t, filename, "[server\naddress = ")
+
+	if err := v.Reload(); err == nil {
+		t.Error("config.Reload(): invalid file error, got 'nil', want error")
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:9090")
+
+	if calls != 1 {
+		t.Errorf("config.OnChange(): calls got '%d', want '%d'", calls, 1)
+	}
+}
+
+func TestViper_ReloadWithSaveTarget(t *testing.T) {
+	dir := t.TempDir()
+	filename, saveTarget := filepath.Join(dir, "test.toml"), filepath.Join(dir, "out.toml")
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:8080\"\n")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename), config.WithSaveTarget(saveTarget))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:9090\"\n")
+
+	if err = vcfg.(*config.ViperConf).Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:9090")
+
+	if got := vcfg.(*config.ViperConf).LoadReport().ConfigFile; got != filename {
+		t.Errorf("config.LoadReport(): config file, got '%s', want '%s'", got, filename)
+	}
+
+	if err = vcfg.Save(); err != nil {
+		t.Fatalf("config.Save(): error, got '%s', want 'nil'", err)
+	}
+
+	if _, err = os.Stat(saveTarget); err != nil {
+		t.Errorf("config.Save(): save target, got '%s', want 'nil'", err)
+	}
+}
+
+func TestViperConfD_Watch(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	filename := filepath.Join(dir, "test.toml")
+
+	if err := os.Mkdir(confd, 0o700); err != nil {
+		t.Fatalf("os.Mkdir(): error, got '%s', want 'nil'", err)
+	}
+
+	writeTestFile(t, filename, "[server]\naddress = \"127.0.0.1:8080\"\n")
+	writeTestFile(t, filepath.Join(confd, "00-first.toml"), "[server]\nport = 8080\n")
+
+	vcfg := config.NewViperConfD("test", confd, filename, filepath.Join(dir, "fallback.toml"))
+	v, ok := vcfg.(*config.ViperConfD)
+	if !ok {
+		t.Fatal("config.Watch(): vcfg not config.ViperConfD")
+	}
+
+	changed := make(chan struct{}, 10)
+	v.OnChange(func(_, _ map[string]interface{}) {
+		changed <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := v.Watch(ctx); err != nil {
+		t.Fatalf("config.Watch(): error, got '%s', want 'nil'", err)
+	}
+
+	expectGetInt(t, vcfg, "server.port", 8080)
+
+	writeTestFile(t, filepath.Join(confd, "01-second.toml"), "[server]\nport = 9090\n")
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config.Watch(): timed out waiting for reload")
+	}
+
+	expectGetString(t, vcfg, "server.address", "127.0.0.1:8080")
+	expectGetInt(t, vcfg, "server.port", 9090)
+}
+
+func TestViper_WatchReloadError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[server]\nport = 8080\n[database]\npassword = 'secret'\n")
+
+	vcfg, err := config.New("test",
+		config.WithConfigFiles(filename),
+		config.WithRegistry(validationRegistry(t)),
+		config.WithValidation(),
+	)
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	v, ok := vcfg.(*config.ViperConf)
+	if !ok {
+		t.Fatal("config.Watch(): vcfg not config.ViperConf")
+	}
+
+	failed := make(chan error, 10)
+	v.OnReloadError(func(err error) {
+		failed <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err = v.Watch(ctx); err != nil {
+		t.Fatalf("config.Watch(): error, got '%s', want 'nil'", err)
+	}
+
+	writeTestFile(t, filename, "[server]\nport = 80800\n[database]\npassword = 'secret'\n")
+
+	select {
+	case err = <-failed:
+		if !errors.Is(err, config.ErrOutOfRange) {
+			t.Errorf("config.OnReloadError(): error, got '%s', want '%s'", err, config.ErrOutOfRange)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config.Watch(): timed out waiting for reload error")
+	}
+
+	expectGetInt(t, vcfg, "server.port", 8080)
+}
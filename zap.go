@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidLogConfig is returned when the [log] section holds an invalid setting.
+var ErrInvalidLogConfig = errors.New("invalid log setting")
+
+//nolint:gochecknoglobals // constant lists.
+var (
+	zapEncodings      = []string{"json", "console"}
+	zapTimeEncodings  = []string{"rfc3339nano", "rfc3339", "iso8601", "millis", "nanos", "epoch"}
+	zapLevelEncodings = []string{"capital", "capitalcolor", "color", "lowercase"}
+)
+
+// NewZapConfig returns a zap logger configuration derived from the settings in the configuration.
+//
+// The development config is used when "debug" or "log.development" is true, otherwise the production config
+// is used, and is then adjusted by the [log] section:
+//
+//	[log]
+//	level = "info"                        # debug, info, warn, error, dpanic, panic or fatal
+//	development = false
+//	encoding = "json"                     # json or console
+//	output_paths = ["stderr"]
+//	error_output_paths = ["stderr"]
+//	time_encoding = "iso8601"             # rfc3339nano, rfc3339, iso8601, millis, nanos or epoch
+//	level_encoding = "lowercase"          # capital, capitalcolor, color or lowercase
+//	disable_caller = false
+//	disable_stacktrace = false
+//	initial_fields = { service = "api" }
+//
+//	[log.sampling]                        # an initial of 0 disables sampling
+//	initial = 100
+//	thereafter = 100
+//
+// Invalid settings are ignored and returned in a ValidationError along with the configuration.
+func NewZapConfig(c Conf) (zap.Config, error) {
+	cfg := zap.NewProductionConfig()
+	if c.GetBool("debug") || c.GetBool("log.development") {
+		cfg = zap.NewDevelopmentConfig()
+	}
+
+	verr := &ValidationError{}
+
+	applySetting(c, "log.development", verr, ErrInvalidLogConfig, func(v bool) error {
+		cfg.Development = v
+
+		return nil
+	})
+	applySetting(c, "log.level", verr, ErrInvalidLogConfig, func(v string) error {
+		level, err := zap.ParseAtomicLevel(v)
+		if err == nil {
+			cfg.Level = level
+		}
+
+		return err
+	})
+	applySetting(c, "log.encoding", verr, ErrInvalidLogConfig, func(v string) error {
+		return zapChoice(&cfg.Encoding, v, zapEncodings)
+	})
+	applySetting(c, "log.output_paths", verr, ErrInvalidLogConfig, func(v []string) error {
+		return zapPaths(&cfg.OutputPaths, v)
+	})
+	applySetting(c, "log.error_output_paths", verr, ErrInvalidLogConfig, func(v []string) error {
+		return zapPaths(&cfg.ErrorOutputPaths, v)
+	})
+	applySetting(c, "log.time_encoding", verr, ErrInvalidLogConfig, func(v string) error {
+		var name string
+		if err := zapChoice(&name, v, zapTimeEncodings); err != nil {
+			return err
+		}
+
+		return cfg.EncoderConfig.EncodeTime.UnmarshalText([]byte(name))
+	})
+	applySetting(c, "log.level_encoding", verr, ErrInvalidLogConfig, func(v string) error {
+		var name string
+		if err := zapChoice(&name, v, zapLevelEncodings); err != nil {
+			return err
+		}
+
+		return cfg.EncoderConfig.EncodeLevel.UnmarshalText([]byte(name))
+	})
+	applySetting(c, "log.disable_caller", verr, ErrInvalidLogConfig, func(v bool) error {
+		cfg.DisableCaller = v
+
+		return nil
+	})
+	applySetting(c, "log.disable_stacktrace", verr, ErrInvalidLogConfig, func(v bool) error {
+		cfg.DisableStacktrace = v
+
+		return nil
+	})
+	applySetting(c, "log.initial_fields", verr, ErrInvalidLogConfig, func(v map[string]interface{}) error {
+		cfg.InitialFields = v
+
+		return nil
+	})
+	zapSampling(c, &cfg, verr)
+
+	if len(verr.Violations) > 0 {
+		return cfg, verr
+	}
+
+	return cfg, nil
+}
+
+// zapSampling applies the log.sampling section, an initial of zero disables sampling.
+func zapSampling(c Conf, cfg *zap.Config, verr *ValidationError) {
+	if !c.IsSet("log.sampling.initial") && !c.IsSet("log.sampling.thereafter") {
+		return
+	}
+
+	sampling := &zap.SamplingConfig{Initial: 100, Thereafter: 100} //nolint:mnd // zap production defaults.
+	if cfg.Sampling != nil {
+		sampling.Initial, sampling.Thereafter = cfg.Sampling.Initial, cfg.Sampling.Thereafter
+	}
+
+	before := len(verr.Violations)
+
+	for _, s := range []struct {
+		key string
+		dst *int
+	}{
+		{"log.sampling.initial", &sampling.Initial},
+		{"log.sampling.thereafter", &sampling.Thereafter},
+	} {
+		applySetting(c, s.key, verr, ErrInvalidLogConfig, func(v int) error {
+			if v < 0 {
+				return fmt.Errorf("%w: %d is negative", ErrOutOfRange, v)
+			}
+
+			*s.dst = v
+
+			return nil
+		})
+	}
+
+	switch {
+	case len(verr.Violations) > before:
+	case sampling.Initial == 0:
+		cfg.Sampling = nil
+	default:
+		cfg.Sampling = sampling
+	}
+}
+
+// zapChoice sets dst to the lower-cased value if it is one of the choices.
+func zapChoice(dst *string, val string, choices []string) error {
+	val = strings.ToLower(val)
+	if !slices.Contains(choices, val) {
+		return fmt.Errorf("%w: \"%s\" is not one of %s", ErrNotAllowed, val, strings.Join(choices, ", "))
+	}
+
+	*dst = val
+
+	return nil
+}
+
+// zapPaths sets dst to the paths if there is at least one.
+func zapPaths(dst *[]string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("%w: at least one path is required", ErrNotAllowed)
+	}
+
+	*dst = paths
+
+	return nil
+}
+
+// zapLevel returns the level configured by the settings, see NewZapConfig.
+func zapLevel(c Conf) zapcore.Level {
+	cfg, _ := NewZapConfig(c)
+
+	return cfg.Level.Level()
+}
+
+// ZapLevel returns a zap.AtomicLevel that is kept in sync with the configured level, it is updated whenever
+// the configuration is reloaded or changed at runtime, eg. with SetString("log.level", "debug").
+// Every call returns the same level.
+func (v *ViperConf) ZapLevel() zap.AtomicLevel {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if v.level == nil {
+		level := zap.NewAtomicLevelAt(zapLevel(v.snapshot()))
+		v.level = &level
+	}
+
+	return *v.level
+}
+
+// ZapLogger builds a zap.Logger from ZapConfig using ZapLevel, so that the level of the logger follows the
+// configuration without a restart. Other settings of the [log] section only take effect when a new logger is built.
+func (v *ViperConf) ZapLogger(opts ...zap.Option) (*zap.Logger, error) {
+	cfg := v.ZapConfig()
+	cfg.Level = v.ZapLevel()
+
+	logger, err := cfg.Build(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build logger: %w", err)
+	}
+
+	return logger, nil
+}
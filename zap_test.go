@@ -0,0 +1,222 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/na4ma4/config"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewZapConfig_LogSection(t *testing.T) {
+	vcfg, err := config.NewMemoryConfFromString("toml", `[log]
+level = "warn"
+encoding = "console"
+output_paths = ["stdout", "/var/log/test.log"]
+error_output_paths = ["stdout"]
+time_encoding = "RFC3339"
+disable_caller = true
+disable_stacktrace = true
+initial_fields = { service = "api" }
+
+[log.sampling]
+initial = 10
+thereafter = 50
+`)
+	if err != nil {
+		t.Fatalf("config.NewMemoryConfFromString(): error, got '%s', want 'nil'", err)
+	}
+
+	cfg, err := config.NewZapConfig(vcfg)
+	if err != nil {
+		t.Fatalf("config.NewZapConfig(): error, got '%s', want 'nil'", err)
+	}
+
+	if got := cfg.Level.Level(); got != zapcore.WarnLevel {
+		t.Errorf("config.NewZapConfig(): level, got '%s', want '%s'", got, zapcore.WarnLevel)
+	}
+
+	if cfg.Encoding != "console" {
+		t.Errorf("config.NewZapConfig(): encoding, got '%s', want 'console'", cfg.Encoding)
+	}
+
+	if diff := cmp.Diff(cfg.OutputPaths, []string{"stdout", "/var/log/test.log"}); diff != "" {
+		t.Errorf("config.NewZapConfig(): output paths -got +want:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(cfg.ErrorOutputPaths, []string{"stdout"}); diff != "" {
+		t.Errorf("config.NewZapConfig(): error output paths -got +want:\n%s", diff)
+	}
+
+	if !cfg.DisableCaller || !cfg.DisableStacktrace {
+		t.Errorf("config.NewZapConfig(): caller and stacktrace, got '%t' and '%t', want disabled",
+			cfg.DisableCaller, cfg.DisableStacktrace)
+	}
+
+	if diff := cmp.Diff(cfg.InitialFields, map[string]interface{}{"service": "api"}); diff != "" {
+		t.Errorf("config.NewZapConfig(): initial fields -got +want:\n%s", diff)
+	}
+
+	if cfg.Sampling == nil || cfg.Sampling.Initial != 10 || cfg.Sampling.Thereafter != 50 {
+		t.Errorf("config.NewZapConfig(): sampling, got '%+v', want initial 10 and thereafter 50", cfg.Sampling)
+	}
+
+	if cfg.EncoderConfig.EncodeTime == nil {
+		t.Error("config.NewZapConfig(): time encoder, got 'nil', want rfc3339")
+	}
+}
+
+func TestNewZapConfig_Development(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantDev  bool
+		sampling bool
+	}{
+		{"default", map[string]interface{}{}, false, true},
+		{"debug", map[string]interface{}{"debug": true}, true, false},
+		{"log.development", map[string]interface{}{"log": map[string]interface{}{"development": true}}, true, false},
+		{"sampling disabled", map[string]interface{}{"log": map[string]interface{}{
+			"sampling": map[string]interface{}{"initial": 0},
+		}}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewZapConfig(config.NewMemoryConf(tt.settings))
+			if err != nil {
+				t.Fatalf("config.NewZapConfig(): error, got '%s', want 'nil'", err)
+			}
+
+			if cfg.Development != tt.wantDev {
+				t.Errorf("config.NewZapConfig(): development mode, got '%t', want '%t'", cfg.Development, tt.wantDev)
+			}
+
+			if (cfg.Sampling != nil) != tt.sampling {
+				t.Errorf("config.NewZapConfig(): sampling, got '%+v', want enabled '%t'", cfg.Sampling, tt.sampling)
+			}
+		})
+	}
+}
+
+func TestNewZapConfig_Invalid(t *testing.T) {
+	vcfg, err := config.NewMemoryConfFromString("toml", `[log]
+level = "loud"
+encoding = "xml"
+output_paths = []
+time_encoding = "sundial"
+
+[log.sampling]
+initial = -1
+thereafter = -2
+`)
+	if err != nil {
+		t.Fatalf("config.NewMemoryConfFromString(): error, got '%s', want 'nil'", err)
+	}
+
+	cfg, err := config.NewZapConfig(vcfg)
+
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("config.NewZapConfig(): error, got '%v', want ValidationError", err)
+	}
+
+	keys := []string{}
+	for _, v := range verr.Violations {
+		keys = append(keys, v.Key)
+	}
+
+	if diff := cmp.Diff(keys, []string{
+		"log.level", "log.encoding", "log.output_paths", "log.time_encoding", "log.sampling.initial",
+		"log.sampling.thereafter",
+	}); diff != "" {
+		t.Errorf("config.NewZapConfig(): violations -got +want:\n%s", diff)
+	}
+
+	if !errors.Is(err, config.ErrInvalidLogConfig) || !errors.Is(err, config.ErrNotAllowed) {
+		t.Errorf("config.NewZapConfig(): error does not wrap the cause: %s", err)
+	}
+
+	if cfg.Encoding != "json" || cfg.Level.Level() != zapcore.InfoLevel || cfg.Sampling == nil {
+		t.Errorf("config.NewZapConfig(): invalid settings were applied, got '%+v'", cfg)
+	}
+
+	if got := vcfg.ZapConfig(); got.Encoding != "json" {
+		t.Errorf("config.ZapConfig(): encoding, got '%s', want 'json'", got.Encoding)
+	}
+}
+
+func TestViper_ZapLevelFollowsConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.toml")
+	writeTestFile(t, filename, "[log]\nlevel = \"warn\"\n")
+
+	vcfg, err := config.New("test", config.WithConfigFiles(filename))
+	if err != nil {
+		t.Fatalf("config.New(): error, got '%s', want 'nil'", err)
+	}
+
+	vc := vcfg.(*config.ViperConf)
+	level := vc.ZapLevel()
+
+	expectLevel := func(want zapcore.Level) {
+		t.Helper()
+
+		if got := level.Level(); got != want {
+			t.Errorf("config.ZapLevel(): level, got '%s', want '%s'", got, want)
+		}
+	}
+
+	expectLevel(zapcore.WarnLevel)
+
+	writeTestFile(t, filename, "[log]\nlevel = \"error\"\n")
+
+	if err = vc.Reload(); err != nil {
+		t.Fatalf("config.Reload(): error, got '%s', want 'nil'", err)
+	}
+
+	expectLevel(zapcore.ErrorLevel)
+
+	vc.SetString("log.level", "debug")
+	expectLevel(zapcore.DebugLevel)
+
+	vc.SetString("log.level", "loud")
+	expectLevel(zapcore.InfoLevel)
+
+	vc.SetString("log.level", "warn")
+	vc.Unset("log.level")
+	expectLevel(zapcore.InfoLevel)
+
+	if other := vc.ZapLevel(); other.Level() != level.Level() {
+		t.Errorf("config.ZapLevel(): second call, got '%s', want '%s'", other.Level(), level.Level())
+	}
+}
+
+func TestViper_ZapLogger(t *testing.T) {
+	logfile := filepath.Join(t.TempDir(), "test.log")
+
+	vcfg := config.NewViperConfig("test")
+	vcfg.Set("log", map[string]interface{}{"level": "info", "output_paths": []string{logfile}})
+
+	logger, err := vcfg.(*config.ViperConf).ZapLogger()
+	if err != nil {
+		t.Fatalf("config.ZapLogger(): error, got '%s', want 'nil'", err)
+	}
+
+	logger.Debug("hidden")
+	vcfg.SetString("log.level", "debug")
+	logger.Debug("shown")
+	_ = logger.Sync()
+
+	data, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatalf("os.ReadFile(): error, got '%s', want 'nil'", err)
+	}
+
+	if strings.Contains(string(data), "hidden") || !strings.Contains(string(data), "shown") {
+		t.Errorf("config.ZapLogger(): log output, got '%s', want only the message logged at debug", data)
+	}
+}